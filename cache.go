@@ -0,0 +1,185 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 설정이 없을 때 캐시에 보관하는 최대 항목 수
+const defaultCacheMaxEntries = 1000
+
+// Cache는 이미지 내용 해시를 키로 OCR 결과와 생성된 이름을 디스크에 저장한다.
+// 항목마다 JSON 파일 하나를 쓰고, 최대 개수를 넘으면 가장 오래 사용하지 않은 항목부터 지운다.
+// nil Cache는 아무것도 저장하지 않는다.
+type Cache struct {
+	dir        string
+	maxEntries int
+	mu         sync.Mutex
+}
+
+func cacheDir() string {
+	return filepath.Join(configDir(), "cache")
+}
+
+func NewCache(dir string, maxEntries int) *Cache {
+	if maxEntries <= 0 {
+		maxEntries = defaultCacheMaxEntries
+	}
+	return &Cache{dir: dir, maxEntries: maxEntries}
+}
+
+// openCache는 설정에 따라 캐시를 연다. 캐시를 끈 경우 nil을 반환한다.
+func openCache(cfg Config) *Cache {
+	if !cfg.CacheEnabled() {
+		return nil
+	}
+	return NewCache(cacheDir(), cfg.CacheMaxEntries)
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get은 key에 저장된 값을 v로 읽는다. 적중하면 수정 시각을 갱신해 LRU 순서를 유지한다.
+func (c *Cache) Get(key string, v any) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false
+	}
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return true
+}
+
+// Put은 v를 key로 저장하고 최대 개수를 넘는 오래된 항목을 지운다.
+func (c *Cache) Put(key string, v any) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// OCR 결과는 privacy 규칙 판단에 쓰도록 가리기 전 원문을 보관하므로 본인만 읽을 수 있게 한다.
+	// 이전 버전이 0755로 만든 디렉토리도 좁힌다.
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+	if err := os.Chmod(c.dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path(key), data, 0600); err != nil {
+		return err
+	}
+	return c.evict()
+}
+
+func (c *Cache) evict() error {
+	entries, err := c.entries()
+	if err != nil || len(entries) <= c.maxEntries {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+	for _, e := range entries[:len(entries)-c.maxEntries] {
+		os.Remove(e.path)
+	}
+	return nil
+}
+
+type cacheFile struct {
+	path    string
+	modTime time.Time
+}
+
+func (c *Cache) entries() ([]cacheFile, error) {
+	dirEntries, err := os.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var files []cacheFile
+	for _, e := range dirEntries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cacheFile{path: filepath.Join(c.dir, e.Name()), modTime: info.ModTime()})
+	}
+	return files, nil
+}
+
+// Clear는 모든 항목을 지우고 지운 개수를 반환한다.
+func (c *Cache) Clear() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.entries()
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range entries {
+		if err := os.Remove(e.path); err != nil {
+			return 0, err
+		}
+	}
+	return len(entries), nil
+}
+
+// hashFile은 파일 내용의 SHA-256 해시를 반환한다.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ocrCacheKey는 같은 이미지라도 OCR 설정이 다르면 다른 키가 되도록 만든다.
+func ocrCacheKey(cfg Config, imageHash string) string {
+	return fmt.Sprintf("ocr|%s|%s|%s|%s|%v", imageHash, cfg.OCREngine,
+		strings.Join(cfg.OCRLanguages, ","), cfg.OCRLevel, cfg.LanguageCorrection())
+}
+
+// nameCacheKey는 이미지, 프로바이더, 프롬프트, 이름 규칙, 프롬프트에 들어간 OCR 텍스트로 키를 만든다.
+func nameCacheKey(cfg Config, imageHash string, ocrResult OCRResult) string {
+	ocrSum := sha256.Sum256([]byte(ocrResult.Prominent()))
+	return fmt.Sprintf("name|%s|%s|%s|%d|%d|%s|%s|%d-%d|%s|%s|%s", imageHash, cfg.Provider, promptVersion(),
+		cfg.MaxFileNameLen, cfg.Candidates(), cfg.NameLanguage, cfg.NameCase, cfg.NameMinWords, cfg.NameMaxWords, loadGlossary(cfg).version(),
+		examplesVersion(selectExamples(cfg, truncate(ocrResult.Prominent(), maxPromptOCRRunes))), hex.EncodeToString(ocrSum[:8]))
+}
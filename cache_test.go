@@ -0,0 +1,143 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheGetPut(t *testing.T) {
+	c := NewCache(t.TempDir(), 10)
+
+	var missing string
+	if c.Get("nope", &missing) {
+		t.Error("Get on empty cache should miss")
+	}
+
+	want := OCRResult{Text: "Hello", HasText: true, Lines: []OCRLine{{Text: "Hello", Confidence: 0.9}}}
+	if err := c.Put("ocr|abc", want); err != nil {
+		t.Fatal(err)
+	}
+	var got OCRResult
+	if !c.Get("ocr|abc", &got) {
+		t.Fatal("Get after Put should hit")
+	}
+	if got.Text != want.Text || !got.HasText || len(got.Lines) != 1 || got.Lines[0].Confidence != 0.9 {
+		t.Errorf("Get = %+v, want %+v", got, want)
+	}
+}
+
+func TestCachePermissions(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	os.Mkdir(dir, 0755)
+	c := NewCache(dir, 10)
+	if err := c.Put("ocr|abc", OCRResult{Text: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(dir); info.Mode().Perm() != 0700 {
+		t.Errorf("cache dir mode = %v, want 0700", info.Mode().Perm())
+	}
+	if info, _ := os.Stat(c.path("ocr|abc")); info.Mode().Perm() != 0600 {
+		t.Errorf("cache file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestCacheNil(t *testing.T) {
+	var c *Cache
+	if err := c.Put("k", "v"); err != nil {
+		t.Errorf("nil cache Put should be a no-op, got %v", err)
+	}
+	var v string
+	if c.Get("k", &v) {
+		t.Error("nil cache Get should miss")
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	dir := t.TempDir()
+	c := NewCache(dir, 2)
+
+	c.Put("a", "1")
+	c.Put("b", "2")
+	// a를 b보다 최근에 사용한 것으로 만든다
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(c.path("b"), old, old)
+	var v string
+	c.Get("a", &v)
+
+	c.Put("c", "3")
+
+	if c.Get("b", &v) {
+		t.Error("least recently used entry should be evicted")
+	}
+	if !c.Get("a", &v) || !c.Get("c", &v) {
+		t.Error("recent entries should remain")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("cache has %d entries, want 2", len(entries))
+	}
+}
+
+func TestCacheClear(t *testing.T) {
+	c := NewCache(filepath.Join(t.TempDir(), "cache"), 10)
+
+	if n, err := c.Clear(); err != nil || n != 0 {
+		t.Errorf("Clear on missing dir = %d, %v, want 0, nil", n, err)
+	}
+
+	c.Put("a", "1")
+	c.Put("b", "2")
+	n, err := c.Clear()
+	if err != nil || n != 2 {
+		t.Errorf("Clear = %d, %v, want 2, nil", n, err)
+	}
+	var v string
+	if c.Get("a", &v) {
+		t.Error("cleared entry should miss")
+	}
+}
+
+func TestCacheKeys(t *testing.T) {
+	base := Config{Provider: ProviderClaude, OCREngine: OCREngineVision, MaxFileNameLen: 80}
+	ocr := OCRResult{Text: "Slack", HasText: true}
+
+	if ocrCacheKey(base, "h1") == ocrCacheKey(base, "h2") {
+		t.Error("different images should have different OCR keys")
+	}
+	tess := base
+	tess.OCREngine = OCREngineTesseract
+	if ocrCacheKey(base, "h1") == ocrCacheKey(tess, "h1") {
+		t.Error("different OCR engines should have different keys")
+	}
+
+	codex := base
+	codex.Provider = ProviderCodex
+	if nameCacheKey(base, "h1", ocr) == nameCacheKey(codex, "h1", ocr) {
+		t.Error("different providers should have different name keys")
+	}
+	if nameCacheKey(base, "h1", ocr) == nameCacheKey(base, "h1", OCRResult{}) {
+		t.Error("different OCR input should have different name keys")
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.png")
+	b := filepath.Join(dir, "b.png")
+	os.WriteFile(a, []byte("same"), 0644)
+	os.WriteFile(b, []byte("same"), 0644)
+
+	ha, err := hashFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hb, _ := hashFile(b)
+	if ha != hb {
+		t.Error("identical content should have identical hash")
+	}
+	if _, err := hashFile(filepath.Join(dir, "missing")); err == nil {
+		t.Error("missing file should return error")
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const cliUsage = `Usage: auto-naming-capture [command]
+
+인자 없이 실행하면 메뉴바 앱으로 동작합니다.
+
+Commands:
+  rename [--interactive] <file>    스크린샷 하나를 리네이밍 (--interactive: 후보 중에서 선택)
+  pending                          승인 대기 중인 리네이밍 목록 (confirm_rename)
+  pending approve <id|all> [name]  승인 (name을 주면 그 이름으로)
+  pending reject <id|all>          거절 (파일은 그대로 둠)
+  cache clear                      OCR/이름 캐시 삭제
+  prompt render <file>             스크린샷에 대해 프로바이더에 보낼 프롬프트 미리보기
+`
+
+// rename --interactive에서 name_candidates가 1 이하일 때 요청할 후보 수
+const defaultInteractiveCandidates = 3
+
+// runCLI는 명령줄 서브커맨드를 실행하고 종료 코드를 반환한다.
+func runCLI(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	switch args[0] {
+	case "rename":
+		return runRenameCommand(args[1:], stdin, stdout, stderr)
+	case "pending":
+		return runPendingCommand(args[1:], stdout, stderr)
+	case "cache":
+		return runCacheCommand(args[1:], stdout, stderr)
+	case "prompt":
+		return runPromptCommand(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, cliUsage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command: %s\n\n%s", args[0], cliUsage)
+		return 2
+	}
+}
+
+// runRenameCommand는 스크린샷 하나를 감시와 같은 방식으로 리네이밍한다.
+// --interactive면 가장 좋은 후보로 바꾼 뒤 다른 후보를 보여주고 고른 이름으로 다시 바꾼다.
+func runRenameCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	interactive := false
+	var files []string
+	for _, arg := range args {
+		switch arg {
+		case "-i", "--interactive":
+			interactive = true
+		default:
+			files = append(files, arg)
+		}
+	}
+	if len(files) != 1 {
+		fmt.Fprint(stderr, cliUsage)
+		return 2
+	}
+	path, err := filepath.Abs(files[0])
+	if err == nil {
+		_, err = os.Stat(path)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "rename failed: %v\n", err)
+		return 1
+	}
+
+	cfg := LoadConfig()
+	if d, ok := cfg.DirFor(path); ok {
+		cfg = cfg.ForDir(d)
+	}
+	if interactive {
+		// 터미널에서 바로 고르므로 승인 대기열을 거치지 않는다
+		cfg.ConfirmRename = false
+		if cfg.NameCandidates <= 1 {
+			cfg.NameCandidates = defaultInteractiveCandidates
+		}
+	}
+
+	result := ProcessScreenshot(cfg, path)
+	if result.Skipped {
+		fmt.Fprintf(stdout, "그대로 둠 (privacy rule %q)\n", result.LocalOnly)
+		return 0
+	}
+	if result.Error != nil {
+		fmt.Fprintf(stderr, "rename failed: %v\n", result.Error)
+		return 1
+	}
+	if result.PendingID != 0 {
+		fmt.Fprintf(stdout, "승인 대기 #%d: %s (pending approve %d)\n", result.PendingID, result.SuggestedName, result.PendingID)
+		return 0
+	}
+	if interactive && len(result.Alternatives) > 0 {
+		result = pickCandidate(cfg, result, stdin, stdout, stderr)
+		if result.Error != nil {
+			fmt.Fprintf(stderr, "rename failed: %v\n", result.Error)
+			return 1
+		}
+	}
+	fmt.Fprintln(stdout, result.NewPath)
+	return 0
+}
+
+// pickCandidate는 후보 목록을 보여주고 번호를 입력받는다. 빈 입력이면 현재 이름을 유지한다.
+func pickCandidate(cfg Config, result RenameResult, stdin io.Reader, stdout, stderr io.Writer) RenameResult {
+	candidates := append([]string{result.SuggestedName}, result.Alternatives...)
+	for i, name := range candidates {
+		marker := " "
+		if i == 0 {
+			marker = "*"
+		}
+		fmt.Fprintf(stdout, "%s %d) %s\n", marker, i+1, name)
+	}
+
+	in := bufio.NewScanner(stdin)
+	for {
+		fmt.Fprintf(stdout, "이름 선택 [1-%d, Enter=1]: ", len(candidates))
+		if !in.Scan() {
+			fmt.Fprintln(stdout)
+			return result
+		}
+		answer := strings.TrimSpace(in.Text())
+		if answer == "" || answer == "1" {
+			return result
+		}
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 1 || n > len(candidates) {
+			fmt.Fprintf(stderr, "1부터 %d 사이의 번호를 입력하세요\n", len(candidates))
+			continue
+		}
+		// 이 프로세스에는 감시자가 없고, 트레이 앱의 감시자는 CLI가 만든 결과 파일을 추적하지 않으므로
+		// 수정으로 기록되지 않는다
+		return SwitchName(cfg, result, candidates[n-1], nil)
+	}
+}
+
+// runPendingCommand는 승인 대기열을 보여주거나 항목을 승인/거절한다.
+func runPendingCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "list" {
+		items, err := ListPending()
+		if err != nil {
+			fmt.Fprintf(stderr, "pending failed: %v\n", err)
+			return 1
+		}
+		if len(items) == 0 {
+			fmt.Fprintln(stdout, "승인 대기 없음")
+		}
+		for _, item := range items {
+			fmt.Fprintf(stdout, "#%d  %s → %s", item.ID, item.Path, item.Name)
+			if len(item.Alternatives) > 0 {
+				fmt.Fprintf(stdout, "  (후보: %s)", strings.Join(item.Alternatives, ", "))
+			}
+			fmt.Fprintln(stdout)
+		}
+		return 0
+	}
+
+	action := args[0]
+	if (action != "approve" && action != "reject") || len(args) < 2 || len(args) > 3 ||
+		(action == "reject" && len(args) == 3) || (args[1] == "all" && len(args) == 3) {
+		fmt.Fprint(stderr, cliUsage)
+		return 2
+	}
+	var ids []int
+	if args[1] == "all" {
+		items, err := ListPending()
+		if err != nil {
+			fmt.Fprintf(stderr, "pending failed: %v\n", err)
+			return 1
+		}
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+	} else {
+		id, err := strconv.Atoi(strings.TrimPrefix(args[1], "#"))
+		if err != nil {
+			fmt.Fprintf(stderr, "invalid id: %s\n", args[1])
+			return 2
+		}
+		ids = []int{id}
+	}
+	var name string
+	if len(args) == 3 {
+		name = args[2]
+	}
+
+	cfg := LoadConfig()
+	code := 0
+	for _, id := range ids {
+		if action == "reject" {
+			if err := RejectPending(id); err != nil {
+				fmt.Fprintf(stderr, "reject #%d failed: %v\n", id, err)
+				code = 1
+				continue
+			}
+			fmt.Fprintf(stdout, "거절 #%d\n", id)
+			continue
+		}
+		// 이 프로세스에는 감시자가 없다
+		result := ApprovePending(cfg, id, name, nil)
+		if result.Error != nil {
+			fmt.Fprintf(stderr, "approve #%d failed: %v\n", id, result.Error)
+			code = 1
+			continue
+		}
+		fmt.Fprintln(stdout, result.NewPath)
+	}
+	return code
+}
+
+func runCacheCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 || args[0] != "clear" {
+		fmt.Fprint(stderr, cliUsage)
+		return 2
+	}
+
+	n, err := NewCache(cacheDir(), 0).Clear()
+	if err != nil {
+		fmt.Fprintf(stderr, "cache clear failed: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "캐시 삭제: %d개 항목\n", n)
+	return 0
+}
+
+// runPromptCommand는 실제 처리와 같은 OCR, 민감 정보 가림, 템플릿을 거친 최종 프롬프트를 출력한다.
+// 프로바이더는 호출하지 않는다.
+func runPromptCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 2 || args[0] != "render" {
+		fmt.Fprint(stderr, cliUsage)
+		return 2
+	}
+	path, err := filepath.Abs(args[1])
+	if err == nil {
+		_, err = os.Stat(path)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "prompt render failed: %v\n", err)
+		return 1
+	}
+
+	cfg := LoadConfig()
+	if d, ok := cfg.DirFor(path); ok {
+		cfg = cfg.ForDir(d)
+	}
+	cache := openCache(cfg)
+	imageHash, err := hashFile(path)
+	if err != nil {
+		cache = nil
+	}
+	var result RenameResult
+	ocrResult, _ := extractText(cfg, path, cache, imageHash, &result)
+	ocrResult, _ = redactOCR(cfg, ocrResult)
+	data := newPromptData(cfg, path, ocrResult)
+
+	fmt.Fprintf(stdout, "# provider: %s\n\n", cfg.Provider)
+	if cfg.Provider == ProviderCodex {
+		fmt.Fprintf(stdout, "## prompt\n%s\n", buildCodexPrompt(data))
+		return 0
+	}
+	fmt.Fprintf(stdout, "## system\n%s\n\n## prompt\n%s\n", buildSystemPrompt(data), buildPrompt(data))
+	return 0
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCLI(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	t.Run("cache clear", func(t *testing.T) {
+		c := NewCache(cacheDir(), 0)
+		c.Put("a", "1")
+		c.Put("b", "2")
+
+		var stdout, stderr bytes.Buffer
+		if code := runCLI([]string{"cache", "clear"}, nil, &stdout, &stderr); code != 0 {
+			t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), "2개") {
+			t.Errorf("stdout = %q, want cleared count", stdout.String())
+		}
+	})
+
+	t.Run("cache without subcommand", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		if code := runCLI([]string{"cache"}, nil, &stdout, &stderr); code != 2 {
+			t.Errorf("exit code = %d, want 2", code)
+		}
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		if code := runCLI([]string{"bogus"}, nil, &stdout, &stderr); code != 2 {
+			t.Errorf("exit code = %d, want 2", code)
+		}
+		if !strings.Contains(stderr.String(), "unknown command") {
+			t.Errorf("stderr = %q, want unknown command message", stderr.String())
+		}
+	})
+
+	t.Run("help", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		if code := runCLI([]string{"help"}, nil, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "cache clear") {
+			t.Errorf("help exit = %d, stdout = %q", code, stdout.String())
+		}
+	})
+}
+
+func TestRunRename(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	claude, _ := fakeCLI(t, "slack-chat\nslack-배포-논의\nslack-dm")
+	if err := os.MkdirAll(configDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"provider": "claude", "claude_path": "` + claude + `", "ocr_policy": "never", "cache": false}`
+	if err := os.WriteFile(configPath(), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		input string
+		want  string
+	}{
+		// 후보를 하나만 요청하므로 여러 줄 출력은 거부되고 기본 이름을 쓴다
+		{"non interactive", nil, "", "2025-01-15_screenshot.png"},
+		{"keep top", []string{"--interactive"}, "\n", "2025-01-15_slack-chat.png"},
+		{"pick third", []string{"-i"}, "9\nx\n3\n", "2025-01-15_slack-dm.png"},
+		{"eof keeps top", []string{"-i"}, "", "2025-01-15_slack-chat.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			shot := writeTestPNG(t, dir, "Screenshot 2025-01-15 at 12.30.45.png", false)
+
+			var stdout, stderr bytes.Buffer
+			args := append(append([]string{"rename"}, tt.args...), shot)
+			if code := runCLI(args, strings.NewReader(tt.input), &stdout, &stderr); code != 0 {
+				t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+			}
+			want := filepath.Join(dir, tt.want)
+			if !strings.HasSuffix(strings.TrimSpace(stdout.String()), want) {
+				t.Errorf("stdout = %q, want final path %q", stdout.String(), want)
+			}
+			if _, err := os.Stat(want); err != nil {
+				t.Errorf("renamed file should exist: %v", err)
+			}
+		})
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCLI([]string{"rename"}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("missing file exit code = %d, want 2", code)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 )
 
 type Provider string
@@ -15,14 +17,219 @@ const (
 	ProviderCodex  Provider = "codex"
 )
 
+// WatchMode는 파일 변경을 감지하는 방식이다.
+type WatchMode string
+
+const (
+	WatchModeAuto   WatchMode = "auto"
+	WatchModeNotify WatchMode = "fsnotify"
+	WatchModePoll   WatchMode = "poll"
+)
+
+// 기본 파일명 템플릿: {date}, {time}, {name}, {original}(원본 파일명)을 치환한다
+const defaultNameTemplate = "{date}_{name}"
+
+// WatchDir은 감시 디렉토리 하나와 그 디렉토리에만 적용되는 설정이다.
+// 비어있는 필드는 전역 설정을 따른다.
+type WatchDir struct {
+	Path        string   `json:"path"`
+	Presets     []string `json:"presets,omitempty"`
+	Patterns    []string `json:"patterns,omitempty"`
+	Provider    Provider `json:"provider,omitempty"`
+	Template    string   `json:"template,omitempty"`
+	Destination string   `json:"destination,omitempty"`
+	Recursive   bool     `json:"recursive,omitempty"`
+	Exclude     []string `json:"exclude,omitempty"`
+	Enabled     *bool    `json:"enabled,omitempty"`
+}
+
+// IsEnabled는 이 디렉토리를 감시할지 여부다 (기본값 true).
+func (d WatchDir) IsEnabled() bool {
+	return d.Enabled == nil || *d.Enabled
+}
+
+// Contains는 dir이 이 감시 디렉토리(recursive면 하위 트리 포함)에 속하는지 확인한다.
+func (d WatchDir) Contains(dir string) bool {
+	root := filepath.Clean(expandHome(d.Path))
+	dir = filepath.Clean(dir)
+	if dir == root {
+		return true
+	}
+	if !d.Recursive {
+		return false
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	return !d.Excluded(rel)
+}
+
+// Excluded는 루트 기준 상대 경로가 exclude glob과 일치하는지 확인한다.
+// glob은 경로 전체 또는 경로의 각 구성 요소(node_modules, .git 등)에 대해 비교한다.
+func (d WatchDir) Excluded(rel string) bool {
+	parts := strings.Split(rel, string(filepath.Separator))
+	for _, pattern := range d.Exclude {
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+		for _, part := range parts {
+			if ok, _ := filepath.Match(pattern, part); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 type Config struct {
-	ScreenshotDir  string   `json:"screenshot_dir"`
-	OCRHelperPath  string   `json:"ocr_helper_path"`
-	Provider       Provider `json:"provider"`
-	ClaudePath     string   `json:"claude_path"`
-	CodexPath      string   `json:"codex_path"`
-	MaxFileNameLen int      `json:"max_filename_length"`
-	Enabled        bool     `json:"enabled"`
+	ScreenshotDir         string          `json:"screenshot_dir"`
+	WatchDirs             []WatchDir      `json:"watch_dirs,omitempty"`
+	Presets               []string        `json:"presets,omitempty"`
+	Patterns              []string        `json:"patterns,omitempty"`
+	OCREngine             OCREngine       `json:"ocr_engine"`
+	OCRHelperPath         string          `json:"ocr_helper_path"`
+	TesseractPath         string          `json:"tesseract_path"`
+	OCRLanguages          []string        `json:"ocr_languages,omitempty"`
+	OCRLevel              string          `json:"ocr_level"`
+	OCRLanguageCorrection *bool           `json:"ocr_language_correction,omitempty"`
+	OCRTimeout            int             `json:"ocr_timeout"`
+	OCRPolicy             OCRPolicy       `json:"ocr_policy"`
+	Cache                 *bool           `json:"cache,omitempty"`
+	CacheMaxEntries       int             `json:"cache_max_entries"`
+	PrivacyRules          []PrivacyRule   `json:"privacy_rules,omitempty"`
+	Redact                *bool           `json:"redact,omitempty"`
+	RedactPatterns        []string        `json:"redact_patterns,omitempty"`
+	Preprocess            *bool           `json:"preprocess,omitempty"`
+	PreprocessMaxDim      int             `json:"preprocess_max_dimension"`
+	PreprocessQuality     int             `json:"preprocess_quality"`
+	DuplicateAction       DuplicateAction `json:"duplicate_action"`
+	DuplicateThreshold    int             `json:"duplicate_threshold"`
+	DuplicatesDir         string          `json:"duplicates_dir,omitempty"`
+	Provider              Provider        `json:"provider"`
+	ClaudePath            string          `json:"claude_path"`
+	CodexPath             string          `json:"codex_path"`
+	NameLanguage          NameLanguage    `json:"name_language,omitempty"`
+	NameCase              NameCase        `json:"name_case,omitempty"`
+	NameMinWords          int             `json:"name_min_words,omitempty"`
+	NameMaxWords          int             `json:"name_max_words,omitempty"`
+	ASCIIFilenames        bool            `json:"ascii_filenames,omitempty"`
+	Normalization         Normalization   `json:"unicode_normalization,omitempty"`
+	Glossary              Glossary        `json:"glossary,omitempty"`
+	GlossaryLearn         bool            `json:"glossary_learn,omitempty"`
+	LearnRenames          *bool           `json:"learn_renames,omitempty"`
+	PromptExamples        int             `json:"prompt_examples"`
+	NameCandidates        int             `json:"name_candidates"`
+	ConfirmRename         bool            `json:"confirm_rename,omitempty"`
+	Notifications         *bool           `json:"notifications,omitempty"`
+	MaxFileNameLen        int             `json:"max_filename_length"`
+	NameTemplate          string          `json:"name_template"`
+	Destination           string          `json:"destination,omitempty"`
+	MaxWatches            int             `json:"max_watches"`
+	WatchMode             WatchMode       `json:"watch_mode"`
+	PollInterval          int             `json:"poll_interval"`
+	LogLevel              string          `json:"log_level"`
+	LogFormat             LogFormat       `json:"log_format"`
+	LogMaxSizeMB          int             `json:"log_max_size_mb"`
+	Enabled               bool            `json:"enabled"`
+}
+
+// Dirs는 감시할 디렉토리 목록을 반환한다.
+// watch_dirs가 없으면 screenshot_dir 하나만 감시한다.
+func (c Config) Dirs() []WatchDir {
+	if len(c.WatchDirs) > 0 {
+		return c.WatchDirs
+	}
+	return []WatchDir{{Path: c.ScreenshotDir}}
+}
+
+// ForDir은 디렉토리별 설정을 전역 설정 위에 덮어쓴 설정을 반환한다.
+func (c Config) ForDir(d WatchDir) Config {
+	c.ScreenshotDir = d.Path
+	if len(d.Presets) > 0 || len(d.Patterns) > 0 {
+		c.Presets, c.Patterns = d.Presets, d.Patterns
+	}
+	if d.Provider != "" {
+		c.Provider = d.Provider
+	}
+	if d.Template != "" {
+		c.NameTemplate = d.Template
+	}
+	if d.Destination != "" {
+		c.Destination = d.Destination
+	}
+	c.Enabled = c.Enabled && d.IsEnabled()
+	return c
+}
+
+// LanguageCorrection은 OCR 언어 교정 사용 여부다 (기본값 true).
+func (c Config) LanguageCorrection() bool {
+	return c.OCRLanguageCorrection == nil || *c.OCRLanguageCorrection
+}
+
+// CacheEnabled는 OCR/이름 캐시 사용 여부다 (기본값 true).
+func (c Config) CacheEnabled() bool {
+	return c.Cache == nil || *c.Cache
+}
+
+// RedactEnabled는 프롬프트에 넣기 전 OCR 텍스트의 민감 정보 가림 여부다 (기본값 true).
+func (c Config) RedactEnabled() bool {
+	return c.Redact == nil || *c.Redact
+}
+
+// PreprocessEnabled는 AI 분석 전 이미지 축소/재압축 사용 여부다 (기본값 true).
+func (c Config) PreprocessEnabled() bool {
+	return c.Preprocess == nil || *c.Preprocess
+}
+
+// LearnRenamesEnabled는 결과 파일을 직접 고친 이름을 예시로 저장할지 여부다 (기본값 true).
+func (c Config) LearnRenamesEnabled() bool {
+	return c.LearnRenames == nil || *c.LearnRenames
+}
+
+// NotificationsEnabled는 리네이밍 결과를 데스크톱 알림으로 보여줄지 여부다 (기본값 true).
+func (c Config) NotificationsEnabled() bool {
+	return c.Notifications == nil || *c.Notifications
+}
+
+// Candidates는 프로바이더에 요청할 파일명 후보 수다 (1~maxNameCandidates, 기본값 1).
+func (c Config) Candidates() int {
+	return min(max(c.NameCandidates, 1), maxNameCandidates)
+}
+
+// ScreenshotPatterns는 프리셋과 사용자 정규식으로 스크린샷 파일명 패턴을 만든다.
+func (c Config) ScreenshotPatterns() []*regexp.Regexp {
+	return compilePatterns(c.Presets, c.Patterns)
+}
+
+// DirFor는 파일이 속한 감시 디렉토리를 찾는다.
+// 여러 디렉토리에 속하면 가장 깊은(구체적인) 디렉토리를 반환한다.
+func (c Config) DirFor(path string) (WatchDir, bool) {
+	dir := filepath.Dir(path)
+	var found WatchDir
+	ok := false
+	for _, d := range c.Dirs() {
+		if !d.Contains(dir) {
+			continue
+		}
+		if !ok || len(expandHome(d.Path)) > len(expandHome(found.Path)) {
+			found, ok = d, true
+		}
+	}
+	return found, ok
+}
+
+// SetDirEnabled는 감시 디렉토리 하나를 켜거나 끈다.
+// screenshot_dir만 설정된 경우 watch_dirs로 옮긴 뒤 변경한다.
+func (c *Config) SetDirEnabled(path string, enabled bool) {
+	if len(c.WatchDirs) == 0 {
+		c.WatchDirs = c.Dirs()
+	}
+	for i := range c.WatchDirs {
+		if c.WatchDirs[i].Path == path {
+			c.WatchDirs[i].Enabled = &enabled
+		}
+	}
 }
 
 func configDir() string {
@@ -30,10 +237,35 @@ func configDir() string {
 	return filepath.Join(home, ".config", "auto-naming-capture")
 }
 
+// writePrivateFile은 OCR 텍스트처럼 본인만 읽어야 하는 데이터를 0700 디렉토리의 0600 파일로 쓴다.
+// 이전 버전이 0755/0644로 만든 디렉토리와 파일도 좁힌다.
+func writePrivateFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if err := os.Chmod(dir, 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
+}
+
 func configPath() string {
 	return filepath.Join(configDir(), "config.json")
 }
 
+// expandHome은 "~/"로 시작하는 경로를 홈 디렉토리 기준으로 바꾼다.
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, path[1:])
+	}
+	return path
+}
+
 func detectScreenshotDir() string {
 	out, err := exec.Command("defaults", "read", "com.apple.screencapture", "location").Output()
 	if err == nil {
@@ -46,6 +278,110 @@ func detectScreenshotDir() string {
 	return filepath.Join(home, "Desktop")
 }
 
+// systemOCRLanguages는 시스템 로케일에서 OCR 언어 목록을 한 번만 감지한다.
+var systemOCRLanguages = sync.OnceValue(detectOCRLanguages)
+
+// detectOCRLanguages는 macOS의 AppleLanguages 또는 LANGUAGE/LC_ALL/LANG 환경변수에서
+// OCR 언어 목록을 만든다. 화면에는 영어 UI가 섞여 있으므로 en-US는 항상 포함한다.
+func detectOCRLanguages() []string {
+	var locales []string
+	if out, err := exec.Command("defaults", "read", "-g", "AppleLanguages").Output(); err == nil {
+		locales = parseAppleLanguages(string(out))
+	}
+	if len(locales) == 0 {
+		for _, env := range []string{"LANGUAGE", "LC_ALL", "LC_MESSAGES", "LANG"} {
+			if v := os.Getenv(env); v != "" {
+				locales = append(locales, strings.Split(v, ":")...)
+			}
+		}
+	}
+	return ocrLanguagesFor(locales)
+}
+
+// parseAppleLanguages는 `defaults read -g AppleLanguages` 출력 ("(\n "ko-KR",\n "en-US"\n)")을 파싱한다.
+func parseAppleLanguages(out string) []string {
+	var langs []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.Trim(strings.TrimSpace(line), `(),"`)
+		if line != "" {
+			langs = append(langs, line)
+		}
+	}
+	return langs
+}
+
+// visionLanguages는 Vision 텍스트 인식(VNRecognizeTextRequest revision 3)이 지원하는 언어다.
+var visionLanguages = []string{
+	"en-US", "fr-FR", "it-IT", "de-DE", "es-ES", "pt-BR", "zh-Hans", "zh-Hant",
+	"yue-Hans", "yue-Hant", "ko-KR", "ja-JP", "ru-RU", "uk-UA", "th-TH", "vi-VT",
+}
+
+// fallbackOCRLanguages는 로케일에서 영어 외 언어를 찾지 못했을 때 쓰는 기존 기본값이다.
+var fallbackOCRLanguages = []string{"en-US", "ko-KR"}
+
+// 중국어는 Vision이 지역이 아닌 문자 체계(간체/번체)로 구분한다
+var chineseScripts = map[string]string{
+	"zh-CN": "zh-Hans",
+	"zh-SG": "zh-Hans",
+	"zh-TW": "zh-Hant",
+	"zh-HK": "zh-Hant",
+	"zh-MO": "zh-Hant",
+}
+
+// visionLanguage는 로케일을 Vision이 지원하는 언어로 바꾼다.
+// 지역까지 같은 언어가 없으면 언어 코드만 맞는 첫 번째 언어를 쓴다 (fr-CA → fr-FR).
+func visionLanguage(locale string) (string, bool) {
+	if script, ok := chineseScripts[locale]; ok {
+		return script, true
+	}
+	if strings.HasPrefix(locale, "zh-Hans") || strings.HasPrefix(locale, "zh-Hant") {
+		return locale[:7], true
+	}
+	lang, _, _ := strings.Cut(locale, "-")
+	for _, v := range visionLanguages {
+		if strings.EqualFold(v, locale) {
+			return v, true
+		}
+	}
+	for _, v := range visionLanguages {
+		if prefix, _, _ := strings.Cut(v, "-"); strings.EqualFold(prefix, lang) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// ocrLanguagesFor는 "ja_JP.UTF-8", "zh-Hans-CN" 같은 로케일 목록을 Vision OCR 언어 목록으로 바꾼다.
+// Vision이 지원하지 않는 언어는 빼고, 영어만 남으면 기존 기본값(en-US, ko-KR)을 쓴다.
+func ocrLanguagesFor(locales []string) []string {
+	var langs []string
+	seen := make(map[string]bool)
+	add := func(lang string) {
+		if !seen[lang] {
+			seen[lang] = true
+			langs = append(langs, lang)
+		}
+	}
+
+	for _, locale := range locales {
+		// 인코딩/수식어 제거 후 ja_JP → ja-JP
+		locale, _, _ = strings.Cut(locale, ".")
+		locale, _, _ = strings.Cut(locale, "@")
+		locale = strings.ReplaceAll(locale, "_", "-")
+		if locale == "" || locale == "C" || locale == "POSIX" {
+			continue
+		}
+		if lang, ok := visionLanguage(locale); ok {
+			add(lang)
+		}
+	}
+	add("en-US")
+	if len(langs) == 1 {
+		return append([]string(nil), fallbackOCRLanguages...)
+	}
+	return langs
+}
+
 func detectExecutablePath(name string) string {
 	path, err := exec.LookPath(name)
 	if err != nil {
@@ -63,13 +399,31 @@ func DefaultConfig() Config {
 	}
 
 	return Config{
-		ScreenshotDir:  detectScreenshotDir(),
-		OCRHelperPath:  ocrHelper,
-		Provider:       ProviderClaude,
-		ClaudePath:     detectExecutablePath("claude"),
-		CodexPath:      detectExecutablePath("codex"),
-		MaxFileNameLen: 80,
-		Enabled:        true,
+		ScreenshotDir:      detectScreenshotDir(),
+		OCREngine:          defaultOCREngine(),
+		OCRHelperPath:      ocrHelper,
+		TesseractPath:      detectExecutablePath("tesseract"),
+		OCRLevel:           OCRLevelAccurate,
+		OCRTimeout:         defaultOCRTimeout,
+		OCRPolicy:          OCRPolicyAlways,
+		CacheMaxEntries:    defaultCacheMaxEntries,
+		PreprocessMaxDim:   defaultPreprocessMaxDim,
+		PreprocessQuality:  defaultPreprocessQuality,
+		PromptExamples:     defaultPromptExamples,
+		DuplicateAction:    DuplicateOff,
+		DuplicateThreshold: defaultDuplicateThreshold,
+		Provider:           ProviderClaude,
+		ClaudePath:         detectExecutablePath("claude"),
+		CodexPath:          detectExecutablePath("codex"),
+		MaxFileNameLen:     80,
+		NameTemplate:       defaultNameTemplate,
+		MaxWatches:         4096,
+		WatchMode:          WatchModeAuto,
+		PollInterval:       2,
+		LogLevel:           defaultLogLevel,
+		LogFormat:          LogFormatText,
+		LogMaxSizeMB:       defaultLogMaxSizeMB,
+		Enabled:            true,
 	}
 }
 
@@ -89,6 +443,50 @@ func LoadConfig() Config {
 	if fileCfg.ScreenshotDir != "" {
 		cfg.ScreenshotDir = fileCfg.ScreenshotDir
 	}
+	if fileCfg.OCREngine != "" {
+		cfg.OCREngine = fileCfg.OCREngine
+	}
+	if fileCfg.TesseractPath != "" {
+		cfg.TesseractPath = fileCfg.TesseractPath
+	}
+	if len(fileCfg.OCRLanguages) > 0 {
+		cfg.OCRLanguages = fileCfg.OCRLanguages
+	}
+	if fileCfg.OCRLevel != "" {
+		cfg.OCRLevel = fileCfg.OCRLevel
+	}
+	if fileCfg.OCRTimeout > 0 {
+		cfg.OCRTimeout = fileCfg.OCRTimeout
+	}
+	if fileCfg.OCRPolicy != "" {
+		cfg.OCRPolicy = fileCfg.OCRPolicy
+	}
+	if fileCfg.CacheMaxEntries > 0 {
+		cfg.CacheMaxEntries = fileCfg.CacheMaxEntries
+	}
+	cfg.Cache = fileCfg.Cache
+	if len(fileCfg.PrivacyRules) > 0 {
+		cfg.PrivacyRules = fileCfg.PrivacyRules
+	}
+	cfg.Redact = fileCfg.Redact
+	if len(fileCfg.RedactPatterns) > 0 {
+		cfg.RedactPatterns = fileCfg.RedactPatterns
+	}
+	cfg.Preprocess = fileCfg.Preprocess
+	if fileCfg.PreprocessMaxDim > 0 {
+		cfg.PreprocessMaxDim = fileCfg.PreprocessMaxDim
+	}
+	if fileCfg.PreprocessQuality > 0 {
+		cfg.PreprocessQuality = fileCfg.PreprocessQuality
+	}
+	if fileCfg.DuplicateAction != "" {
+		cfg.DuplicateAction = fileCfg.DuplicateAction
+	}
+	if fileCfg.DuplicateThreshold > 0 {
+		cfg.DuplicateThreshold = fileCfg.DuplicateThreshold
+	}
+	cfg.DuplicatesDir = fileCfg.DuplicatesDir
+	cfg.OCRLanguageCorrection = fileCfg.OCRLanguageCorrection
 	if fileCfg.OCRHelperPath != "" {
 		cfg.OCRHelperPath = fileCfg.OCRHelperPath
 	}
@@ -101,9 +499,63 @@ func LoadConfig() Config {
 	if fileCfg.Provider != "" {
 		cfg.Provider = fileCfg.Provider
 	}
+	if fileCfg.NameLanguage != "" {
+		cfg.NameLanguage = fileCfg.NameLanguage
+	}
+	if fileCfg.NameCase != "" {
+		cfg.NameCase = fileCfg.NameCase
+	}
+	if fileCfg.NameMinWords > 0 {
+		cfg.NameMinWords = fileCfg.NameMinWords
+	}
+	if fileCfg.NameMaxWords > 0 {
+		cfg.NameMaxWords = fileCfg.NameMaxWords
+	}
+	cfg.ASCIIFilenames = fileCfg.ASCIIFilenames
+	if fileCfg.Normalization != "" {
+		cfg.Normalization = fileCfg.Normalization
+	}
+	if len(fileCfg.Glossary) > 0 {
+		cfg.Glossary = fileCfg.Glossary
+	}
+	cfg.GlossaryLearn = fileCfg.GlossaryLearn
+	cfg.ConfirmRename = fileCfg.ConfirmRename
+	cfg.LearnRenames = fileCfg.LearnRenames
+	cfg.Notifications = fileCfg.Notifications
+	if fileCfg.NameCandidates > 0 {
+		cfg.NameCandidates = fileCfg.NameCandidates
+	}
+	if fileCfg.PromptExamples > 0 {
+		cfg.PromptExamples = fileCfg.PromptExamples
+	}
 	if fileCfg.MaxFileNameLen > 0 {
 		cfg.MaxFileNameLen = fileCfg.MaxFileNameLen
 	}
+	if fileCfg.NameTemplate != "" {
+		cfg.NameTemplate = fileCfg.NameTemplate
+	}
+	if fileCfg.WatchMode != "" {
+		cfg.WatchMode = fileCfg.WatchMode
+	}
+	if fileCfg.PollInterval > 0 {
+		cfg.PollInterval = fileCfg.PollInterval
+	}
+	if fileCfg.LogLevel != "" {
+		cfg.LogLevel = fileCfg.LogLevel
+	}
+	if fileCfg.LogFormat != "" {
+		cfg.LogFormat = fileCfg.LogFormat
+	}
+	if fileCfg.LogMaxSizeMB > 0 {
+		cfg.LogMaxSizeMB = fileCfg.LogMaxSizeMB
+	}
+	if fileCfg.MaxWatches > 0 {
+		cfg.MaxWatches = fileCfg.MaxWatches
+	}
+	cfg.WatchDirs = fileCfg.WatchDirs
+	cfg.Presets = fileCfg.Presets
+	cfg.Patterns = fileCfg.Patterns
+	cfg.Destination = fileCfg.Destination
 	cfg.Enabled = fileCfg.Enabled
 
 	return cfg
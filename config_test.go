@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -151,3 +152,251 @@ func TestConfigJSON_PartialOverride(t *testing.T) {
 		t.Errorf("MaxFileNameLen = %d, want 0 (zero value)", cfg.MaxFileNameLen)
 	}
 }
+
+func TestConfigDirs(t *testing.T) {
+	t.Run("falls back to screenshot dir", func(t *testing.T) {
+		cfg := Config{ScreenshotDir: "/shots"}
+		dirs := cfg.Dirs()
+		if len(dirs) != 1 || dirs[0].Path != "/shots" || !dirs[0].IsEnabled() {
+			t.Errorf("Dirs() = %+v, want single enabled /shots", dirs)
+		}
+	})
+
+	t.Run("watch dirs take precedence", func(t *testing.T) {
+		cfg := Config{
+			ScreenshotDir: "/shots",
+			WatchDirs:     []WatchDir{{Path: "/a"}, {Path: "/b"}},
+		}
+		dirs := cfg.Dirs()
+		if len(dirs) != 2 || dirs[0].Path != "/a" || dirs[1].Path != "/b" {
+			t.Errorf("Dirs() = %+v, want /a and /b", dirs)
+		}
+	})
+
+	t.Run("enabled defaults to true", func(t *testing.T) {
+		var cfg Config
+		data := `{"watch_dirs": [{"path": "/a"}, {"path": "/b", "enabled": false}]}`
+		if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		if !cfg.WatchDirs[0].IsEnabled() || cfg.WatchDirs[1].IsEnabled() {
+			t.Errorf("WatchDirs = %+v, want /a enabled and /b disabled", cfg.WatchDirs)
+		}
+	})
+}
+
+func TestConfigForDir(t *testing.T) {
+	global := Config{
+		Provider:     ProviderClaude,
+		NameTemplate: defaultNameTemplate,
+		Enabled:      true,
+	}
+
+	t.Run("empty fields inherit global", func(t *testing.T) {
+		got := global.ForDir(WatchDir{Path: "/a"})
+		if got.ScreenshotDir != "/a" {
+			t.Errorf("ScreenshotDir = %q, want /a", got.ScreenshotDir)
+		}
+		if got.Provider != ProviderClaude || got.NameTemplate != defaultNameTemplate {
+			t.Errorf("ForDir should inherit global settings, got %+v", got)
+		}
+		if !got.Enabled {
+			t.Error("Enabled should be true")
+		}
+	})
+
+	t.Run("directory overrides", func(t *testing.T) {
+		got := global.ForDir(WatchDir{
+			Path:        "/a",
+			Provider:    ProviderCodex,
+			Template:    "{name}",
+			Destination: "/archive",
+		})
+		if got.Provider != ProviderCodex {
+			t.Errorf("Provider = %q, want codex", got.Provider)
+		}
+		if got.NameTemplate != "{name}" {
+			t.Errorf("NameTemplate = %q, want {name}", got.NameTemplate)
+		}
+		if got.Destination != "/archive" {
+			t.Errorf("Destination = %q, want /archive", got.Destination)
+		}
+	})
+
+	t.Run("disabled directory", func(t *testing.T) {
+		disabled := false
+		if global.ForDir(WatchDir{Path: "/a", Enabled: &disabled}).Enabled {
+			t.Error("disabled directory should disable processing")
+		}
+	})
+
+	t.Run("globally disabled", func(t *testing.T) {
+		off := global
+		off.Enabled = false
+		if off.ForDir(WatchDir{Path: "/a"}).Enabled {
+			t.Error("global Enabled=false should disable every directory")
+		}
+	})
+}
+
+func TestConfigDirFor(t *testing.T) {
+	cfg := Config{WatchDirs: []WatchDir{{Path: "/a"}, {Path: "/b/"}}}
+
+	tests := []struct {
+		name   string
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{"first dir", "/a/Screenshot.png", "/a", true},
+		{"trailing slash in config", "/b/Screenshot.png", "/b/", true},
+		{"subdirectory not matched", "/a/sub/Screenshot.png", "", false},
+		{"unknown dir", "/c/Screenshot.png", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := cfg.DirFor(tt.path)
+			if ok != tt.wantOK || got.Path != tt.want {
+				t.Errorf("DirFor(%q) = %q, %v, want %q, %v", tt.path, got.Path, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestConfigSetDirEnabled(t *testing.T) {
+	t.Run("migrates screenshot dir", func(t *testing.T) {
+		cfg := Config{ScreenshotDir: "/shots"}
+		cfg.SetDirEnabled("/shots", false)
+		if len(cfg.WatchDirs) != 1 || cfg.WatchDirs[0].IsEnabled() {
+			t.Errorf("WatchDirs = %+v, want disabled /shots", cfg.WatchDirs)
+		}
+	})
+
+	t.Run("toggles only matching dir", func(t *testing.T) {
+		cfg := Config{WatchDirs: []WatchDir{{Path: "/a"}, {Path: "/b"}}}
+		cfg.SetDirEnabled("/b", false)
+		if !cfg.WatchDirs[0].IsEnabled() || cfg.WatchDirs[1].IsEnabled() {
+			t.Errorf("WatchDirs = %+v, want only /b disabled", cfg.WatchDirs)
+		}
+	})
+}
+
+func TestWatchDirContains(t *testing.T) {
+	flat := WatchDir{Path: "/root"}
+	tree := WatchDir{Path: "/root", Recursive: true, Exclude: []string{"node_modules", ".git", "build/*"}}
+
+	tests := []struct {
+		name string
+		d    WatchDir
+		dir  string
+		want bool
+	}{
+		{"root itself", flat, "/root", true},
+		{"flat ignores subdirectory", flat, "/root/sub", false},
+		{"recursive subdirectory", tree, "/root/sub/deeper", true},
+		{"excluded component", tree, "/root/app/node_modules/pkg", false},
+		{"excluded dot dir", tree, "/root/.git", false},
+		{"excluded relative glob", tree, "/root/build/out", false},
+		{"glob only for its level", tree, "/root/build", true},
+		{"sibling with common prefix", tree, "/rootless", false},
+		{"parent", tree, "/", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.Contains(tt.dir); got != tt.want {
+				t.Errorf("Contains(%q) = %v, want %v", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigDirForMostSpecific(t *testing.T) {
+	cfg := Config{WatchDirs: []WatchDir{
+		{Path: "/root", Recursive: true},
+		{Path: "/root/captures", Provider: ProviderCodex},
+	}}
+
+	got, ok := cfg.DirFor("/root/captures/shot.png")
+	if !ok || got.Path != "/root/captures" {
+		t.Errorf("DirFor = %q, want /root/captures", got.Path)
+	}
+	got, ok = cfg.DirFor("/root/other/shot.png")
+	if !ok || got.Path != "/root" {
+		t.Errorf("DirFor = %q, want /root", got.Path)
+	}
+}
+
+func TestOCRLanguagesFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		locales []string
+		want    []string
+	}{
+		{"korean mac", []string{"ko-KR", "en-US"}, []string{"ko-KR", "en-US"}},
+		{"posix locale", []string{"ja_JP.UTF-8"}, []string{"ja-JP", "en-US"}},
+		{"modifier removed", []string{"de_DE@euro"}, []string{"de-DE", "en-US"}},
+		{"chinese region to script", []string{"zh_CN.UTF-8", "zh-TW"}, []string{"zh-Hans", "zh-Hant", "en-US"}},
+		{"chinese script with region", []string{"zh-Hant-HK"}, []string{"zh-Hant", "en-US"}},
+		{"region mapped to supported", []string{"fr_CA", "fr", "pt-PT", "vi-VN"}, []string{"fr-FR", "pt-BR", "vi-VT", "en-US"}},
+		{"unsupported dropped", []string{"nl_NL", "ja-JP"}, []string{"ja-JP", "en-US"}},
+
+		// 영어 외 언어가 없으면 기존 기본값
+		{"english locale", []string{"en_GB.UTF-8"}, []string{"en-US", "ko-KR"}},
+		{"only unsupported", []string{"nl_NL", "sv-SE"}, []string{"en-US", "ko-KR"}},
+		{"c locale ignored", []string{"C", "POSIX", ""}, []string{"en-US", "ko-KR"}},
+		{"empty", nil, []string{"en-US", "ko-KR"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ocrLanguagesFor(tt.locales)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ocrLanguagesFor(%q) = %q, want %q", tt.locales, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAppleLanguages(t *testing.T) {
+	out := "(\n    \"ko-KR\",\n    \"en-US\",\n    \"ja-JP\"\n)\n"
+	got := parseAppleLanguages(out)
+	if strings.Join(got, ",") != "ko-KR,en-US,ja-JP" {
+		t.Errorf("parseAppleLanguages = %q", got)
+	}
+}
+
+func TestConfigLanguageCorrection(t *testing.T) {
+	on, off := true, false
+	if !(Config{}).LanguageCorrection() {
+		t.Error("language correction should default to true")
+	}
+	if !(Config{OCRLanguageCorrection: &on}).LanguageCorrection() {
+		t.Error("explicit true should be respected")
+	}
+	if (Config{OCRLanguageCorrection: &off}).LanguageCorrection() {
+		t.Error("explicit false should be respected")
+	}
+}
+
+func TestWritePrivateFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "config")
+	path := filepath.Join(dir, "data.json")
+	// 이전 버전이 만든 0755 디렉토리와 0644 파일
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(path, []byte("old"), 0644)
+
+	if err := writePrivateFile(path, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("dir = %v, %v, want 0700", info, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("file = %v, %v, want 0600", info, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("data = %q, want %q", data, "new")
+	}
+}
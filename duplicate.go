@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math/bits"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DuplicateAction은 최근 스크린샷과 거의 같은 스크린샷을 처리하는 방법이다.
+type DuplicateAction string
+
+const (
+	// 중복 검사 안 함 (기본값)
+	DuplicateOff DuplicateAction = "off"
+	// 이전 이름에 순번을 붙여 재사용 (프로바이더 호출 생략)
+	DuplicateSequence DuplicateAction = "sequence"
+	// 중복 폴더로 이동
+	DuplicateMove DuplicateAction = "move"
+	// 확인 후 삭제
+	DuplicateDelete DuplicateAction = "delete"
+)
+
+const (
+	// 해밍 거리가 이 값 이하면 거의 같은 이미지로 본다 (64비트 dHash 기준)
+	defaultDuplicateThreshold = 5
+	// 이 시간 안에 찍은 스크린샷끼리만 비교
+	duplicateWindow = 10 * time.Minute
+	// 비교 대상으로 기억하는 최근 스크린샷 수
+	maxRecentShots = 50
+	// 중복 폴더 기본 이름 (스크린샷 디렉토리 기준)
+	defaultDuplicatesDir = "duplicates"
+)
+
+// perceptualHash는 이미지의 dHash를 계산한다. 9x8 격자의 평균 밝기를 구해
+// 가로로 이웃한 칸보다 밝으면 1인 64비트 값이다. 크기 조절이나 재압축에도 거의 변하지 않는다.
+func perceptualHash(path string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return 0, err
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return 0, fmt.Errorf("empty image")
+	}
+
+	var cells [8][9]float64
+	for cy := 0; cy < 8; cy++ {
+		y0, y1 := b.Min.Y+cy*h/8, b.Min.Y+max((cy+1)*h/8, cy*h/8+1)
+		for cx := 0; cx < 9; cx++ {
+			x0, x1 := b.Min.X+cx*w/9, b.Min.X+max((cx+1)*w/9, cx*w/9+1)
+			// 큰 이미지도 빠르게: 칸마다 최대 16x16 픽셀만 샘플링
+			sy, sx := max(1, (y1-y0)/16), max(1, (x1-x0)/16)
+			var sum, n float64
+			for y := y0; y < y1 && y < b.Max.Y; y += sy {
+				for x := x0; x < x1 && x < b.Max.X; x += sx {
+					sum += float64(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+					n++
+				}
+			}
+			cells[cy][cx] = sum / n
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if cells[y][x] > cells[y][x+1] {
+				hash |= 1 << (y*8 + x)
+			}
+		}
+	}
+	return hash, nil
+}
+
+func hammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// recentShot은 최근에 이름을 붙인 스크린샷이다.
+type recentShot struct {
+	hash uint64
+	path string
+	name string
+	// 같은 이름을 재사용한 횟수 (순번 접미사용)
+	seq int
+	at  time.Time
+}
+
+// shotHistory는 최근 스크린샷의 perceptual hash를 기억한다.
+type shotHistory struct {
+	mu    sync.Mutex
+	shots []*recentShot
+}
+
+var recentShots = &shotHistory{}
+
+// similar는 threshold 이내로 비슷한 최근 스크린샷 중 가장 가까운 것을 찾는다.
+func (h *shotHistory) similar(hash uint64, threshold int) (*recentShot, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.expire(time.Now())
+	var best *recentShot
+	bestDist := threshold + 1
+	for _, s := range h.shots {
+		if d := hammingDistance(hash, s.hash); d < bestDist {
+			best, bestDist = s, d
+		}
+	}
+	return best, best != nil
+}
+
+func (h *shotHistory) add(hash uint64, path, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.shots = append(h.shots, &recentShot{hash: hash, path: path, name: name, seq: 1, at: time.Now()})
+	if len(h.shots) > maxRecentShots {
+		h.shots = h.shots[len(h.shots)-maxRecentShots:]
+	}
+}
+
+// nextName은 이전 이름에 다음 순번을 붙인 이름을 반환한다 (slack-chat → slack-chat-2).
+func (h *shotHistory) nextName(s *recentShot) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s.seq++
+	s.at = time.Now()
+	return fmt.Sprintf("%s-%d", s.name, s.seq)
+}
+
+func (h *shotHistory) expire(now time.Time) {
+	kept := h.shots[:0]
+	for _, s := range h.shots {
+		if now.Sub(s.at) <= duplicateWindow {
+			kept = append(kept, s)
+		}
+	}
+	h.shots = kept
+}
+
+// duplicatesDir은 중복 스크린샷을 옮길 디렉토리다. 상대 경로는 스크린샷 디렉토리 기준이다.
+func duplicatesDir(cfg Config, screenshotPath string) string {
+	dir := duplicatesDirSetting(cfg)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(screenshotPath), dir)
+	}
+	return dir
+}
+
+func duplicatesDirSetting(cfg Config) string {
+	dir := cfg.DuplicatesDir
+	if dir == "" {
+		dir = defaultDuplicatesDir
+	}
+	return filepath.Clean(expandHome(dir))
+}
+
+// isDuplicatesDir은 path가 어떤 스크린샷 디렉토리의 중복 폴더인지 확인한다.
+// recursive 감시가 중복 폴더로 옮긴 스크린샷을 새 스크린샷으로 다시 처리하지 않도록 쓴다.
+func isDuplicatesDir(cfg Config, path string) bool {
+	if cfg.DuplicateAction != DuplicateMove {
+		return false
+	}
+	dir := duplicatesDirSetting(cfg)
+	path = filepath.Clean(path)
+	if filepath.IsAbs(dir) {
+		return path == dir
+	}
+	// 상대 경로면 path의 끝부분이 설정과 같은지 본다 (duplicates, old/dups 등)
+	return strings.HasSuffix(string(filepath.Separator)+path, string(filepath.Separator)+dir)
+}
+
+// confirmDuplicateDelete는 중복 스크린샷 삭제 여부를 사용자에게 묻는다.
+// macOS 외에서는 확인할 방법이 없으므로 삭제하지 않는다.
+var confirmDuplicateDelete = func(path, original string) bool {
+	if runtime.GOOS != "darwin" {
+		return false
+	}
+	script := fmt.Sprintf(`display dialog "%s\n\n최근 스크린샷(%s)과 거의 같습니다. 삭제할까요?" with title "Auto Naming Capture" buttons {"유지", "삭제"} default button "유지"`,
+		escapeAppleScript(filepath.Base(path)), escapeAppleScript(filepath.Base(original)))
+	out, err := exec.Command("osascript", "-e", script).Output()
+	return err == nil && strings.Contains(string(out), "삭제")
+}
+
+func escapeAppleScript(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeGradientPNG는 가로 그라데이션 위에 사각형을 그린 PNG를 만든다.
+func writeGradientPNG(t *testing.T, path string, w, h int, boxX int) {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(x * 255 / w)
+			if x >= boxX*w/100 && x < (boxX+20)*w/100 && y > h/4 && y < h*3/4 {
+				v = 255 - v
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPerceptualHash(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "original.png")
+	scaled := filepath.Join(dir, "scaled.png")
+	different := filepath.Join(dir, "different.png")
+	writeGradientPNG(t, original, 400, 300, 10)
+	writeGradientPNG(t, scaled, 200, 150, 10)
+	writeGradientPNG(t, different, 400, 300, 70)
+
+	h1, err := perceptualHash(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2, _ := perceptualHash(scaled)
+	h3, _ := perceptualHash(different)
+
+	if d := hammingDistance(h1, h2); d > defaultDuplicateThreshold {
+		t.Errorf("scaled copy distance = %d, want <= %d", d, defaultDuplicateThreshold)
+	}
+	if d := hammingDistance(h1, h3); d <= defaultDuplicateThreshold {
+		t.Errorf("different image distance = %d, want > %d", d, defaultDuplicateThreshold)
+	}
+
+	if _, err := perceptualHash(filepath.Join(dir, "missing.png")); err == nil {
+		t.Error("missing file should return error")
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want int
+	}{
+		{0, 0, 0},
+		{0, 1, 1},
+		{0xFF, 0x0F, 4},
+		{0, ^uint64(0), 64},
+	}
+	for _, tt := range tests {
+		if got := hammingDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("hammingDistance(%x, %x) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestShotHistory(t *testing.T) {
+	h := &shotHistory{}
+	h.add(0b1111, "/shots/a.png", "slack-chat")
+	h.add(0xFFFF0000, "/shots/b.png", "github-pr")
+
+	t.Run("closest within threshold", func(t *testing.T) {
+		s, ok := h.similar(0b0111, 5)
+		if !ok || s.name != "slack-chat" {
+			t.Errorf("similar = %+v, %v, want slack-chat", s, ok)
+		}
+	})
+
+	t.Run("nothing within threshold", func(t *testing.T) {
+		if _, ok := h.similar(0xAAAAAAAAAAAAAAAA, 5); ok {
+			t.Error("distant hash should not match")
+		}
+	})
+
+	t.Run("sequence names", func(t *testing.T) {
+		s, _ := h.similar(0b1111, 5)
+		if got := h.nextName(s); got != "slack-chat-2" {
+			t.Errorf("nextName = %q, want slack-chat-2", got)
+		}
+		if got := h.nextName(s); got != "slack-chat-3" {
+			t.Errorf("nextName = %q, want slack-chat-3", got)
+		}
+	})
+
+	t.Run("old shots expire", func(t *testing.T) {
+		for _, s := range h.shots {
+			s.at = time.Now().Add(-2 * duplicateWindow)
+		}
+		if _, ok := h.similar(0b1111, 5); ok {
+			t.Error("shots outside window should not match")
+		}
+	})
+
+	t.Run("bounded size", func(t *testing.T) {
+		h := &shotHistory{}
+		for i := 0; i < maxRecentShots+10; i++ {
+			h.add(uint64(i), "/p", "n")
+		}
+		if len(h.shots) != maxRecentShots {
+			t.Errorf("len(shots) = %d, want %d", len(h.shots), maxRecentShots)
+		}
+	})
+}
+
+func TestDuplicatesDir(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{"default", "", "/shots/duplicates"},
+		{"relative", "dups", "/shots/dups"},
+		{"absolute", "/archive/dups", "/archive/dups"},
+		{"home", "~/dups", filepath.Join(home, "dups")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := duplicatesDir(Config{DuplicatesDir: tt.dir}, "/shots/a.png"); got != tt.want {
+				t.Errorf("duplicatesDir(%q) = %q, want %q", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsDuplicatesDir(t *testing.T) {
+	tests := []struct {
+		name   string
+		action DuplicateAction
+		dir    string
+		path   string
+		want   bool
+	}{
+		{"default", DuplicateMove, "", "/shots/duplicates", true},
+		{"default in subdirectory", DuplicateMove, "", "/shots/project/duplicates", true},
+		{"other directory", DuplicateMove, "", "/shots/project", false},
+		{"name suffix only", DuplicateMove, "", "/shots/my-duplicates", false},
+		{"nested relative", DuplicateMove, "old/dups", "/shots/old/dups", true},
+		{"absolute", DuplicateMove, "/archive/dups", "/archive/dups", true},
+		{"absolute elsewhere", DuplicateMove, "/archive/dups", "/shots/dups", false},
+		{"not moving duplicates", DuplicateSequence, "", "/shots/duplicates", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{DuplicateAction: tt.action, DuplicatesDir: tt.dir}
+			if got := isDuplicatesDir(cfg, tt.path); got != tt.want {
+				t.Errorf("isDuplicatesDir(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
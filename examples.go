@@ -0,0 +1,188 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// 프롬프트에 넣는 예시 수 기본값
+	defaultPromptExamples = 3
+	// 보관하는 예시 수 상한 (넘치면 오래된 것부터 버린다)
+	maxNameExamples = 200
+)
+
+// NameExample은 우리가 제안한 이름과 사용자가 고친 이름의 쌍이다.
+// OCR 텍스트는 관련 예시를 고르는 데만 쓰고 프롬프트에는 넣지 않는다.
+type NameExample struct {
+	Suggested string    `json:"suggested"`
+	Chosen    string    `json:"chosen"`
+	OCRText   string    `json:"ocr_text,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+// 예시 파일 읽기/쓰기를 직렬화한다
+var examplesMu sync.Mutex
+
+func examplesPath() string {
+	return filepath.Join(configDir(), "examples.json")
+}
+
+func readExamples() ([]NameExample, error) {
+	data, err := os.ReadFile(examplesPath())
+	if err != nil {
+		return nil, err
+	}
+	var examples []NameExample
+	if err := json.Unmarshal(data, &examples); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", examplesPath(), err)
+	}
+	return examples, nil
+}
+
+// addExample은 예시를 저장한다. 같은 제안을 다시 고쳤으면 이전 예시를 바꾼다.
+func addExample(ex NameExample) error {
+	examplesMu.Lock()
+	defer examplesMu.Unlock()
+
+	examples, err := readExamples()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	kept := examples[:0]
+	for _, e := range examples {
+		if e.Suggested != ex.Suggested || e.OCRText != ex.OCRText {
+			kept = append(kept, e)
+		}
+	}
+	examples = append(kept, ex)
+	if len(examples) > maxNameExamples {
+		examples = examples[len(examples)-maxNameExamples:]
+	}
+
+	data, err := json.MarshalIndent(examples, "", "  ")
+	if err != nil {
+		return err
+	}
+	// 예시에는 OCR 텍스트가 들어 있다
+	return writePrivateFile(examplesPath(), data)
+}
+
+// selectExamples는 OCR 텍스트와 단어가 많이 겹치는 예시를, 같으면 최근 것을 먼저 최대 prompt_examples개 고른다.
+func selectExamples(cfg Config, ocrText string) []NameExample {
+	if cfg.PromptExamples <= 0 {
+		return nil
+	}
+	examplesMu.Lock()
+	examples, err := readExamples()
+	examplesMu.Unlock()
+	if err != nil {
+		return nil
+	}
+
+	words := exampleWords(ocrText)
+	scores := make([]float64, len(examples))
+	for i, e := range examples {
+		scores[i] = wordOverlap(words, exampleWords(e.OCRText))
+	}
+	order := make([]int, len(examples))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ia, ib := order[a], order[b]
+		if scores[ia] != scores[ib] {
+			return scores[ia] > scores[ib]
+		}
+		return examples[ia].At.After(examples[ib].At)
+	})
+
+	var selected []NameExample
+	for _, i := range order[:min(cfg.PromptExamples, len(order))] {
+		selected = append(selected, examples[i])
+	}
+	return selected
+}
+
+// examplesVersion은 이름 캐시 키에 쓰는 선택된 예시의 해시다.
+func examplesVersion(examples []NameExample) string {
+	if len(examples) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	for _, e := range examples {
+		fmt.Fprintf(h, "%s\x00%s\x00", e.Suggested, e.Chosen)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:4])
+}
+
+// exampleWords는 OCR 텍스트의 두 글자 이상 단어 집합이다 (소문자).
+func exampleWords(text string) map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(w)) >= 2 {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+// wordOverlap은 두 단어 집합의 Jaccard 유사도다.
+func wordOverlap(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for w := range a {
+		if b[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// chosenName은 사용자가 고친 파일명에서 템플릿 부분을 뺀 이름이다.
+// 결과 파일명의 제안 이름 앞뒤(날짜 등)가 그대로 남아 있으면 그 사이만 쓰고, 아니면 파일명 전체를 쓴다.
+func chosenName(result RenameResult, correctedPath string) string {
+	produced := norm.NFC.String(strings.TrimSuffix(filepath.Base(result.NewPath), filepath.Ext(result.NewPath)))
+	corrected := norm.NFC.String(strings.TrimSuffix(filepath.Base(correctedPath), filepath.Ext(correctedPath)))
+	if prefix, suffix, ok := strings.Cut(produced, result.SuggestedName); ok && result.SuggestedName != "" &&
+		strings.HasPrefix(corrected, prefix) && strings.HasSuffix(corrected, suffix) && len(corrected) > len(prefix)+len(suffix) {
+		return corrected[len(prefix) : len(corrected)-len(suffix)]
+	}
+	return corrected
+}
+
+// recordCorrection은 사용자가 결과 파일 이름을 고쳤을 때 호출된다.
+// 용어집을 학습하고 (learn_renames 설정이면) 제안/선택 쌍을 예시로 저장한다.
+func recordCorrection(cfg Config, result RenameResult, correctedPath string) {
+	learnFromCorrection(cfg, result, correctedPath)
+
+	// local only 규칙 이름, 기본 이름, 중복 순번 이름은 프로바이더 제안이 아니므로 예시로 쓰지 않는다
+	if !cfg.LearnRenamesEnabled() || result.LocalOnly != "" || result.NameRejected != "" || result.DuplicateOf != "" || result.SuggestedName == "" {
+		return
+	}
+	chosen := chosenName(result, correctedPath)
+	if chosen == result.SuggestedName {
+		return
+	}
+	ex := NameExample{Suggested: result.SuggestedName, Chosen: chosen, OCRText: result.PromptOCRText, At: time.Now()}
+	if err := addExample(ex); err != nil {
+		learnLog.Error("예시 저장 실패", "err", err)
+		return
+	}
+	learnLog.Info("예시 저장", "suggested", ex.Suggested, "chosen", ex.Chosen)
+}
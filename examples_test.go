@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChosenName(t *testing.T) {
+	result := RenameResult{NewPath: "/shots/2025-01-15_slack-chat.png", SuggestedName: "slack-chat"}
+	tests := []struct {
+		corrected string
+		want      string
+	}{
+		{"/shots/2025-01-15_slack-standup-notes.png", "slack-standup-notes"},
+		{"/shots/2025-01-15_slack-chat-final.png", "slack-chat-final"},
+		// 템플릿 부분까지 바꾸면 파일명 전체를 쓴다
+		{"/shots/standup-notes.png", "standup-notes"},
+		{"/shots/2025-01-15_.png", "2025-01-15_"},
+		// NFD로 저장된 한글은 NFC로 합친다
+		{"/shots/2025-01-15_\u1109\u1173\u11af\u1105\u1162\u11a8.png", "슬랙"},
+	}
+	for _, tt := range tests {
+		if got := chosenName(result, tt.corrected); got != tt.want {
+			t.Errorf("chosenName(%q) = %q, want %q", tt.corrected, got, tt.want)
+		}
+	}
+}
+
+func TestAddExample(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	for i := range maxNameExamples + 5 {
+		if err := addExample(NameExample{Suggested: fmt.Sprintf("s-%d", i), Chosen: "c"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	examples, err := readExamples()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(examples) != maxNameExamples || examples[0].Suggested != "s-5" {
+		t.Errorf("kept %d examples starting at %q, want %d starting at s-5", len(examples), examples[0].Suggested, maxNameExamples)
+	}
+
+	// 같은 제안을 다시 고치면 이전 예시를 바꾼다
+	addExample(NameExample{Suggested: "s-10", Chosen: "again"})
+	examples, _ = readExamples()
+	var count int
+	for _, e := range examples {
+		if e.Suggested == "s-10" {
+			count++
+		}
+	}
+	if last := examples[len(examples)-1]; count != 1 || last.Chosen != "again" {
+		t.Errorf("re-corrected example count = %d, last = %+v", count, last)
+	}
+	if info, err := os.Stat(examplesPath()); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("examples file = %v, %v, want 0600 file", info, err)
+	}
+}
+
+func TestSelectExamples(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	now := time.Now()
+	for _, ex := range []NameExample{
+		{Suggested: "slack-chat", Chosen: "slack-배포-논의", OCRText: "#deploy 배포 일정 논의", At: now.Add(-3 * time.Hour)},
+		{Suggested: "github-pr", Chosen: "github-pr-1234-review", OCRText: "Pull request #1234 review", At: now.Add(-2 * time.Hour)},
+		{Suggested: "figma-design", Chosen: "figma-온보딩-화면", OCRText: "Onboarding frame", At: now.Add(-time.Hour)},
+		{Suggested: "terminal-log", Chosen: "terminal-build-error", At: now},
+	} {
+		if err := addExample(ex); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	chosen := func(examples []NameExample) []string {
+		var names []string
+		for _, e := range examples {
+			names = append(names, e.Chosen)
+		}
+		return names
+	}
+	tests := []struct {
+		name  string
+		count int
+		ocr   string
+		want  []string
+	}{
+		{"disabled", 0, "배포 일정", nil},
+		{"most similar first", 2, "Pull request review comments", []string{"github-pr-1234-review", "terminal-build-error"}},
+		{"korean overlap", 1, "다음 주 배포 일정", []string{"slack-배포-논의"}},
+		{"no text uses recent", 2, "", []string{"terminal-build-error", "figma-온보딩-화면"}},
+		{"more than stored", 10, "", []string{"terminal-build-error", "figma-온보딩-화면", "github-pr-1234-review", "slack-배포-논의"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := chosen(selectExamples(Config{PromptExamples: tt.count}, tt.ocr))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectExamples = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordCorrection(t *testing.T) {
+	result := RenameResult{
+		NewPath:       "/shots/2025-01-15_slack-chat.png",
+		SuggestedName: "slack-chat",
+		PromptOCRText: "#deploy 배포 일정",
+	}
+	corrected := "/shots/2025-01-15_slack-배포-논의.png"
+	disabled := false
+
+	tests := []struct {
+		name   string
+		cfg    Config
+		result func(RenameResult) RenameResult
+		want   int
+	}{
+		{"records example", Config{}, func(r RenameResult) RenameResult { return r }, 1},
+		{"learn_renames off", Config{LearnRenames: &disabled}, func(r RenameResult) RenameResult { return r }, 0},
+		{"local only name", Config{}, func(r RenameResult) RenameResult { r.LocalOnly = "bank"; return r }, 0},
+		{"fallback name", Config{}, func(r RenameResult) RenameResult { r.NameRejected = "refusal"; return r }, 0},
+		{"duplicate sequence", Config{}, func(r RenameResult) RenameResult { r.DuplicateOf = "/shots/a.png"; return r }, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			recordCorrection(tt.cfg, tt.result(result), corrected)
+			examples, _ := readExamples()
+			if len(examples) != tt.want {
+				t.Fatalf("recorded %d examples, want %d", len(examples), tt.want)
+			}
+			if tt.want > 0 && (examples[0].Chosen != "slack-배포-논의" || examples[0].OCRText != result.PromptOCRText) {
+				t.Errorf("example = %+v", examples[0])
+			}
+		})
+	}
+}
+
+func TestExamplesInPrompt(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	addExample(NameExample{Suggested: "slack-chat", Chosen: "slack-배포-논의", OCRText: "배포 일정", At: time.Now()})
+
+	dir := t.TempDir()
+	shot := writeTestPNG(t, dir, "Screenshot 2025-01-15 at 12.30.45.png", true)
+	claude, argsFile := fakeCLI(t, "slack-배포-회의")
+	cfg := Config{Provider: ProviderClaude, ClaudePath: claude, MaxFileNameLen: 80, OCRPolicy: OCRPolicyNever, PromptExamples: 3}
+
+	result := ProcessScreenshot(cfg, shot)
+	if !result.Success {
+		t.Fatalf("ProcessScreenshot failed: %v", result.Error)
+	}
+	args := strings.Join(readArgs(t, argsFile), "\n")
+	if !strings.Contains(args, "## 사용자 수정 예시") || !strings.Contains(args, "- slack-chat → slack-배포-논의") {
+		t.Errorf("system prompt should include examples, got:\n%s", args)
+	}
+	// 예시의 OCR 텍스트는 프롬프트에 넣지 않는다
+	if strings.Contains(args, "배포 일정") {
+		t.Error("example OCR text should not be sent to the provider")
+	}
+
+	// 사용자가 결과 파일을 고치면 다음 예시가 된다
+	recordCorrection(cfg, result, filepath.Join(dir, "2025-01-15_slack-배포-회의록.png"))
+	if got := selectExamples(cfg, ""); len(got) != 2 || got[0].Chosen != "slack-배포-회의록" {
+		t.Errorf("examples after correction = %+v", got)
+	}
+}
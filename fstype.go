@@ -0,0 +1,32 @@
+package main
+
+// 변경 알림(inotify/FSEvents)을 전달하지 않는 파일 시스템
+var unnotifiedFilesystems = map[string]bool{
+	"nfs":     true,
+	"smbfs":   true,
+	"smb":     true,
+	"smb2":    true,
+	"cifs":    true,
+	"afpfs":   true,
+	"webdav":  true,
+	"fuse":    true,
+	"osxfuse": true,
+	"macfuse": true,
+	"9p":      true,
+}
+
+// needsPolling은 감시 디렉토리 중 알림을 지원하지 않는 파일 시스템이 있는지 확인한다.
+func needsPolling(dirs []WatchDir) bool {
+	for _, d := range dirs {
+		path := expandHome(d.Path)
+		fsType, err := filesystemType(path)
+		if err != nil {
+			continue
+		}
+		if unnotifiedFilesystems[fsType] {
+			watcherLog.Info("알림 없는 파일 시스템 - 폴링으로 감시", "dir", path, "fs_type", fsType)
+			return true
+		}
+	}
+	return false
+}
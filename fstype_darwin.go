@@ -0,0 +1,21 @@
+package main
+
+import (
+	"strings"
+	"syscall"
+)
+
+func filesystemType(path string) (string, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, c := range st.Fstypename {
+		if c == 0 {
+			break
+		}
+		sb.WriteByte(byte(c))
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,21 @@
+package main
+
+import "syscall"
+
+// statfs f_type 매직 넘버 (linux/magic.h)
+var linuxFilesystemMagic = map[uint32]string{
+	0x6969:     "nfs",
+	0x517b:     "smb",
+	0xfe534d42: "smb2",
+	0xff534d42: "cifs",
+	0x65735546: "fuse",
+	0x01021997: "9p",
+}
+
+func filesystemType(path string) (string, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return "", err
+	}
+	return linuxFilesystemMagic[uint32(st.Type)], nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package main
+
+// 파일 시스템 종류를 알 수 없으면 fsnotify를 사용한다
+func filesystemType(path string) (string, error) {
+	return "", nil
+}
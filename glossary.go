@@ -0,0 +1,297 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// 학습할 때 한 번에 바뀐 단어 수 상한 (더 길면 이름을 새로 지은 것으로 본다)
+	maxLearnedTermWords = 3
+	// 같은 문자 체계에서는 오타 수준(편집 거리 이하)으로 바뀌었을 때만 학습한다
+	maxLearnedEditDistance = 2
+	// 음역 비교에서 자음 골격이 이 길이 이상이면 한 글자 차이까지 같은 용어로 본다
+	minFuzzySkeletonLen = 4
+)
+
+// Glossary는 정식 표기 → 별칭 목록이다. 예: {"Slack": ["slack", "슬랙"]}
+type Glossary map[string][]string
+
+// GlossaryEntry는 프롬프트에 넣는 용어집 한 줄이다.
+type GlossaryEntry struct {
+	Canonical string
+	Aliases   []string
+}
+
+// 학습한 용어집 파일 쓰기를 직렬화한다
+var glossaryMu sync.Mutex
+
+func learnedGlossaryPath() string {
+	return filepath.Join(configDir(), "glossary.json")
+}
+
+// loadGlossary는 설정의 용어집과 학습한 용어집을 합친다. 같은 정식 표기는 별칭을 합친다.
+func loadGlossary(cfg Config) Glossary {
+	g := Glossary{}
+	g.merge(cfg.Glossary)
+
+	glossaryMu.Lock()
+	defer glossaryMu.Unlock()
+	if learned, err := readLearnedGlossary(); err == nil {
+		g.merge(learned)
+	}
+	return g
+}
+
+func readLearnedGlossary() (Glossary, error) {
+	data, err := os.ReadFile(learnedGlossaryPath())
+	if err != nil {
+		return nil, err
+	}
+	var g Glossary
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", learnedGlossaryPath(), err)
+	}
+	return g, nil
+}
+
+func (g Glossary) merge(other Glossary) {
+	for canonical, aliases := range other {
+		for _, a := range aliases {
+			if !slices.ContainsFunc(g[canonical], func(s string) bool { return strings.EqualFold(s, a) }) {
+				g[canonical] = append(g[canonical], a)
+			}
+		}
+		if _, ok := g[canonical]; !ok {
+			g[canonical] = nil
+		}
+	}
+}
+
+// Entries는 정식 표기 순으로 정렬한 용어집이다.
+func (g Glossary) Entries() []GlossaryEntry {
+	entries := make([]GlossaryEntry, 0, len(g))
+	for canonical, aliases := range g {
+		entries = append(entries, GlossaryEntry{Canonical: canonical, Aliases: aliases})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Canonical < entries[j].Canonical })
+	return entries
+}
+
+// version은 이름 캐시 키에 쓰는 용어집 해시다.
+func (g Glossary) version() string {
+	if len(g) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(g.Entries())
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:4])
+}
+
+// Normalize는 파일명의 별칭(대소문자 무시, 단어 단위)을 정식 표기로 바꾼다.
+// 별칭이 여러 단어면 연속된 단어가 모두 맞을 때만 바꾸고, 긴 별칭을 먼저 본다.
+func (g Glossary) Normalize(name string) string {
+	if len(g) == 0 {
+		return name
+	}
+	sep := "-"
+	if i := strings.IndexAny(name, "-_"); i >= 0 {
+		sep = name[i : i+1]
+	}
+
+	type rule struct{ from, to []string }
+	var rules []rule
+	for _, e := range g.Entries() {
+		to := nameWords(e.Canonical)
+		for _, alias := range append([]string{e.Canonical}, e.Aliases...) {
+			if from := nameWords(alias); len(from) > 0 {
+				rules = append(rules, rule{from, to})
+			}
+		}
+	}
+	sort.SliceStable(rules, func(i, j int) bool { return len(rules[i].from) > len(rules[j].from) })
+
+	words := nameWords(name)
+	var out []string
+	for i := 0; i < len(words); {
+		matched := false
+		for _, r := range rules {
+			if i+len(r.from) <= len(words) && wordsEqualFold(words[i:i+len(r.from)], r.from) {
+				out = append(out, r.to...)
+				i += len(r.from)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			out = append(out, words[i])
+			i++
+		}
+	}
+	return strings.Join(out, sep)
+}
+
+func wordsEqualFold(a, b []string) bool {
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// learnGlossary는 우리가 붙인 이름(produced)을 사용자가 고친 이름(corrected)과 비교해
+// 바뀐 부분이 같은 용어의 다른 표기로 보이면 (정식 표기, 별칭)을 반환한다.
+// 대소문자만 다른 교체, 오타 수준의 교체, 음역(슬랙 → Slack)만 학습하고
+// 다른 단어로 바꾼 경우(chrome → firefox)는 무시한다.
+func learnGlossary(produced, corrected string) (canonical, alias string, ok bool) {
+	old, fixed := nameWords(produced), nameWords(corrected)
+
+	start := 0
+	for start < len(old) && start < len(fixed) && old[start] == fixed[start] {
+		start++
+	}
+	end := 0
+	for end < len(old)-start && end < len(fixed)-start && old[len(old)-1-end] == fixed[len(fixed)-1-end] {
+		end++
+	}
+	oldSpan, newSpan := old[start:len(old)-end], fixed[start:len(fixed)-end]
+	if len(oldSpan) == 0 || len(newSpan) == 0 || len(oldSpan) > maxLearnedTermWords || len(newSpan) > maxLearnedTermWords {
+		return "", "", false
+	}
+	alias, canonical = strings.Join(oldSpan, "-"), strings.Join(newSpan, "-")
+	if isNumeric(alias) || isNumeric(canonical) {
+		return "", "", false
+	}
+
+	if !sameTerm(alias, canonical) {
+		return "", "", false
+	}
+	return canonical, alias, true
+}
+
+// sameTerm은 a와 b가 같은 용어의 다른 표기인지 여부다.
+func sameTerm(a, b string) bool {
+	if strings.EqualFold(a, b) || editDistance(strings.ToLower(a), strings.ToLower(b)) <= maxLearnedEditDistance {
+		return true
+	}
+	return transliterated(a, b)
+}
+
+// transliterated는 문자 체계가 다른 a와 b를 로마자로 바꿔 자음 골격을 비교한다.
+// 골격이 짧으면 같아야 하고, 길면 한 글자 차이까지 허용한다 (페이스북 → Facebook).
+func transliterated(a, b string) bool {
+	if isASCII(a) == isASCII(b) {
+		return false
+	}
+	sa, sb := consonantSkeleton(toASCII(a)), consonantSkeleton(toASCII(b))
+	if len(sa) < 2 || len(sb) < 2 {
+		return false
+	}
+	if min(len(sa), len(sb)) < minFuzzySkeletonLen {
+		return sa == sb
+	}
+	return editDistance(sa, sb) <= 1
+}
+
+// 로마자 표기에서 소리가 같거나 비슷한 자음은 하나로 본다 (한국어에는 f, v, z, r/l 구분이 없다)
+var skeletonConsonants = map[rune]rune{'c': 'k', 'q': 'k', 'f': 'p', 'v': 'b', 'r': 'l', 'z': 'j'}
+
+// consonantSkeleton은 모음, h, 문자가 아닌 것을 빼고 비슷한 자음을 합친 뒤 연속된 같은 자음을 하나로 줄인다.
+func consonantSkeleton(s string) string {
+	var out []rune
+	for _, r := range strings.ToLower(s) {
+		if r < 'a' || r > 'z' || strings.ContainsRune("aeiouyhw", r) {
+			continue
+		}
+		if m, ok := skeletonConsonants[r]; ok {
+			r = m
+		}
+		if len(out) > 0 && out[len(out)-1] == r {
+			continue
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+func isASCII(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return r >= 0x80 }) == -1
+}
+
+// addLearnedTerm은 학습한 용어를 설정 디렉토리의 glossary.json에 저장한다.
+func addLearnedTerm(canonical, alias string) error {
+	glossaryMu.Lock()
+	defer glossaryMu.Unlock()
+
+	g, err := readLearnedGlossary()
+	if os.IsNotExist(err) {
+		g, err = Glossary{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	g.merge(Glossary{canonical: {alias}})
+
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writePrivateFile(learnedGlossaryPath(), data)
+}
+
+func isNumeric(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) && r != '-' }) == -1
+}
+
+// editDistance는 rune 단위 Levenshtein 거리다.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+// learnFromCorrection은 사용자가 결과 파일 이름을 고쳤을 때 glossary_learn 설정이면
+// 바뀐 용어를 학습한다.
+func learnFromCorrection(cfg Config, result RenameResult, correctedPath string) {
+	if !cfg.GlossaryLearn {
+		return
+	}
+	produced := norm.NFC.String(strings.TrimSuffix(filepath.Base(result.NewPath), filepath.Ext(result.NewPath)))
+	corrected := norm.NFC.String(strings.TrimSuffix(filepath.Base(correctedPath), filepath.Ext(correctedPath)))
+	canonical, alias, ok := learnGlossary(produced, corrected)
+	if !ok {
+		return
+	}
+	if err := addLearnedTerm(canonical, alias); err != nil {
+		learnLog.Error("용어 저장 실패", "err", err)
+		return
+	}
+	learnLog.Info("용어 학습", "alias", alias, "canonical", canonical)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGlossaryNormalize(t *testing.T) {
+	g := Glossary{
+		"Slack":       {"슬랙", "slak"},
+		"GitHub":      {"깃허브", "git-hub"},
+		"Google-Docs": {"구글-문서", "gdocs"},
+	}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"슬랙-대화", "Slack-대화"},
+		{"slack-chat", "Slack-chat"},
+		{"SLAK-chat", "Slack-chat"},
+		{"git-hub-pr-review", "GitHub-pr-review"},
+		{"깃허브_이슈", "GitHub_이슈"},
+		{"구글-문서-회의록", "Google-Docs-회의록"},
+		{"gdocs-draft", "Google-Docs-draft"},
+		// 단어 일부만 같으면 바꾸지 않는다
+		{"slacker-meme", "slacker-meme"},
+		{"문서-구글", "문서-구글"},
+	}
+	for _, tt := range tests {
+		if got := g.Normalize(tt.input); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	if got := (Glossary{}).Normalize("슬랙-대화"); got != "슬랙-대화" {
+		t.Errorf("empty glossary changed name: %q", got)
+	}
+}
+
+func TestLearnGlossary(t *testing.T) {
+	tests := []struct {
+		name      string
+		produced  string
+		corrected string
+		canonical string
+		alias     string
+		ok        bool
+	}{
+		{"app name", "2025-01-15_슬랙-대화", "2025-01-15_Slack-대화", "Slack", "슬랙", true},
+		{"app name without date", "깃허브-pr-리뷰", "GitHub-pr-리뷰", "GitHub", "깃허브", true},
+		{"transliteration to two words", "구글-독스-draft", "Google-Docs-draft", "Google-Docs", "구글-독스", true},
+		{"transliteration one consonant off", "페이스북-광고", "Facebook-광고", "Facebook", "페이스북", true},
+		{"case only", "2025-01-15_slack-jira-board", "2025-01-15_slack-Jira-board", "Jira", "jira", true},
+		{"typo", "figma-dashbord-design", "figma-dashboard-design", "dashboard", "dashbord", true},
+		{"content change", "slack-project-chat", "slack-standup-notes", "", "", false},
+
+		// 앱 자리라도 다른 단어로 바꾼 것은 학습하지 않는다
+		{"unrelated app name", "2025-01-15_chrome-settings", "2025-01-15_firefox-settings", "", "", false},
+		{"unrelated transliteration", "크롬-설정", "Firefox-설정", "", "", false},
+		{"abbreviation", "gdocs-draft", "Google-Docs-draft", "", "", false},
+		{"short skeleton must match", "노트-정리", "Notion-정리", "", "", false},
+		{"app and content change", "2025-01-15_slack-chat", "2025-01-15_Slack-standup", "", "", false},
+		{"unchanged", "slack-chat", "slack-chat", "", "", false},
+		{"word added", "slack-chat", "slack-chat-final", "", "", false},
+		{"date changed", "2025-01-15_slack-chat", "2025-01-16_slack-chat", "", "", false},
+		{"rewritten", "slack-chat", "meeting-notes-for-q3-planning", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canonical, alias, ok := learnGlossary(tt.produced, tt.corrected)
+			if ok != tt.ok || canonical != tt.canonical || alias != tt.alias {
+				t.Errorf("learnGlossary = (%q, %q, %v), want (%q, %q, %v)", canonical, alias, ok, tt.canonical, tt.alias, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLoadGlossary(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := Config{Glossary: Glossary{"Slack": {"슬랙"}}}
+	if err := addLearnedTerm("Slack", "SLACK"); err != nil {
+		t.Fatal(err)
+	}
+	if err := addLearnedTerm("Slack", "슬랙"); err != nil {
+		t.Fatal(err)
+	}
+	if err := addLearnedTerm("Notion", "노션"); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(learnedGlossaryPath()); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("learned glossary = %v, %v, want 0600 file", info, err)
+	}
+
+	want := []GlossaryEntry{
+		{Canonical: "Notion", Aliases: []string{"노션"}},
+		{Canonical: "Slack", Aliases: []string{"슬랙", "SLACK"}},
+	}
+	if got := loadGlossary(cfg).Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("loadGlossary = %+v, want %+v", got, want)
+	}
+
+	// 학습한 파일이 깨져 있으면 설정의 용어집만 쓴다
+	os.WriteFile(learnedGlossaryPath(), []byte("{"), 0644)
+	if got := loadGlossary(cfg).Entries(); len(got) != 1 || got[0].Canonical != "Slack" {
+		t.Errorf("loadGlossary with broken file = %+v", got)
+	}
+}
+
+func TestLearnFromCorrection(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	result := RenameResult{NewPath: "/shots/2025-01-15_슬랙-대화.png"}
+
+	learnFromCorrection(Config{}, result, "/shots/2025-01-15_Slack-대화.png")
+	if _, err := os.Stat(learnedGlossaryPath()); !os.IsNotExist(err) {
+		t.Fatal("should not learn without glossary_learn")
+	}
+
+	learnFromCorrection(Config{GlossaryLearn: true}, result, "/shots/2025-01-15_Slack-대화.png")
+	if got := loadGlossary(Config{}); !reflect.DeepEqual(got, Glossary{"Slack": {"슬랙"}}) {
+		t.Errorf("learned glossary = %v", got)
+	}
+}
+
+func TestGlossaryPromptAndRename(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	shot := writeTestPNG(t, dir, "Screenshot 2025-01-15 at 12.30.45.png", true)
+	claude, argsFile := fakeCLI(t, "슬랙-배포-논의")
+	cfg := Config{
+		Provider: ProviderClaude, ClaudePath: claude, MaxFileNameLen: 80, OCRPolicy: OCRPolicyNever,
+		Glossary: Glossary{"Slack": {"슬랙"}},
+	}
+
+	result := ProcessScreenshot(cfg, shot)
+	if !result.Success {
+		t.Fatalf("ProcessScreenshot failed: %v", result.Error)
+	}
+	if want := filepath.Join(dir, "2025-01-15_Slack-배포-논의.png"); result.NewPath != want {
+		t.Errorf("NewPath = %q, want %q", result.NewPath, want)
+	}
+	if result.SuggestedName != "Slack-배포-논의" {
+		t.Errorf("SuggestedName = %q", result.SuggestedName)
+	}
+	if args := strings.Join(readArgs(t, argsFile), "\n"); !strings.Contains(args, "## 용어집") || !strings.Contains(args, "- Slack (슬랙)") {
+		t.Errorf("system prompt should list glossary, got:\n%s", args)
+	}
+}
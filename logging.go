@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// LogFormat은 로그 한 줄의 형식이다.
+type LogFormat string
+
+const (
+	LogFormatText LogFormat = "text"
+	LogFormatJSON LogFormat = "json"
+)
+
+const (
+	defaultLogLevel     = "info"
+	defaultLogMaxSizeMB = 5
+	// 회전한 로그를 몇 개까지 남길지 (app.log.1 ~ app.log.3)
+	maxLogBackups = 3
+)
+
+// 컴포넌트별 로거. 출력 설정은 setupLogging이 정하는 기본 로거를 따른다.
+var (
+	watcherLog  = componentLogger("watcher")
+	renamerLog  = componentLogger("renamer")
+	namerLog    = componentLogger("namer")
+	ocrLog      = componentLogger("ocr")
+	pendingLog  = componentLogger("pending")
+	notifyLog   = componentLogger("notify")
+	learnLog    = componentLogger("learn")
+	configLog   = componentLogger("config")
+	menuLog     = componentLogger("menu")
+	jobSequence atomic.Uint64
+)
+
+func logPath() string {
+	return filepath.Join(configDir(), "app.log")
+}
+
+// setupLogging은 기본 로거를 stderr와 회전하는 로그 파일에 함께 쓰도록 바꾼다.
+// 메뉴바에서 실행하면 stderr가 버려지므로 로그 파일이 유일한 기록이다.
+// 로그 파일을 열 수 없으면 stderr에만 쓴다.
+func setupLogging(cfg Config) io.Closer {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		level = slog.LevelInfo
+	}
+	out := io.Writer(os.Stderr)
+	file, err := openRotatingFile(logPath(), int64(cfg.LogMaxSizeMB)<<20, maxLogBackups)
+	if err == nil {
+		out = io.MultiWriter(os.Stderr, file)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if cfg.LogFormat == LogFormatJSON {
+		handler = slog.NewJSONHandler(out, opts)
+	} else {
+		handler = slog.NewTextHandler(out, opts)
+	}
+	slog.SetDefault(slog.New(handler))
+
+	if err != nil {
+		configLog.Warn("로그 파일 열기 실패 - stderr에만 기록", "path", logPath(), "err", err)
+		return noLogFile{}
+	}
+	return file
+}
+
+// noLogFile은 로그 파일을 열지 못했을 때 setupLogging이 돌려주는, 닫을 것이 없는 Closer다.
+type noLogFile struct{}
+
+func (noLogFile) Close() error { return nil }
+
+// componentLogger는 component 속성을 붙인 로거를 만든다.
+// 패키지 변수로 만들어도 나중에 바뀐 기본 로거로 출력한다.
+func componentLogger(name string) *slog.Logger {
+	return slog.New(defaultHandler{}).With("component", name)
+}
+
+// nextJobID는 스크린샷 처리 작업마다 붙이는 번호다. 한 작업의 로그를 묶어 볼 때 쓴다.
+func nextJobID() uint64 {
+	return jobSequence.Add(1)
+}
+
+// defaultHandler는 호출 시점의 slog.Default() 핸들러에 위임한다.
+type defaultHandler struct {
+	// 기본 핸들러에 차례로 적용할 With/WithGroup
+	wrap []func(slog.Handler) slog.Handler
+}
+
+func (h defaultHandler) handler() slog.Handler {
+	handler := slog.Default().Handler()
+	for _, w := range h.wrap {
+		handler = w(handler)
+	}
+	return handler
+}
+
+func (h defaultHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return slog.Default().Handler().Enabled(ctx, level)
+}
+
+func (h defaultHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler().Handle(ctx, r)
+}
+
+func (h defaultHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h defaultHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+func (h defaultHandler) with(w func(slog.Handler) slog.Handler) defaultHandler {
+	wrap := make([]func(slog.Handler) slog.Handler, len(h.wrap), len(h.wrap)+1)
+	copy(wrap, h.wrap)
+	return defaultHandler{wrap: append(wrap, w)}
+}
+
+// rotatingFile은 크기가 maxSize를 넘으면 path → path.1 → path.2 ... 로 밀어내는 로그 파일이다.
+type rotatingFile struct {
+	path    string
+	maxSize int64
+	backups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func openRotatingFile(path string, maxSize int64, backups int) (*rotatingFile, error) {
+	if maxSize <= 0 {
+		maxSize = defaultLogMaxSizeMB << 20
+	}
+	f := &rotatingFile{path: path, maxSize: maxSize, backups: backups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	// 로그에는 파일 경로와 제안 이름이 남으므로 본인만 읽을 수 있게 한다.
+	// 이전 버전이 0755/0644로 만든 디렉토리와 파일도 좁힌다.
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if err := os.Chmod(dir, 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if err := file.Chmod(0600); err != nil {
+		file.Close()
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) rotate() error {
+	f.file.Close()
+	os.Remove(fmt.Sprintf("%s.%d", f.path, f.backups))
+	for i := f.backups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+	}
+	if f.backups > 0 {
+		os.Rename(f.path, f.path+".1")
+	} else {
+		os.Remove(f.path)
+	}
+	return f.open()
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// openLogFile은 로그 파일을 기본 앱(macOS: Console)으로 연다.
+func openLogFile() {
+	switch runtime.GOOS {
+	case "darwin":
+		exec.Command("open", logPath()).Start()
+	case "linux":
+		exec.Command("xdg-open", logPath()).Start()
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureLogs는 테스트 동안 기본 로거를 JSON 버퍼로 바꾼다.
+func captureLogs(t *testing.T, level slog.Level) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level})))
+	return &buf
+}
+
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var r map[string]any
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestComponentLogger(t *testing.T) {
+	// 패키지 변수로 만든 로거도 나중에 바뀐 기본 로거로 출력한다
+	log := componentLogger("test").With("job", 7)
+	buf := captureLogs(t, slog.LevelInfo)
+
+	log.WithGroup("ocr").Info("완료", "chars", 12)
+	log.Debug("숨김")
+
+	records := logRecords(t, buf)
+	if len(records) != 1 {
+		t.Fatalf("records = %v, want only the info record", records)
+	}
+	r := records[0]
+	if r["component"] != "test" || r["job"] != float64(7) || r["msg"] != "완료" {
+		t.Errorf("record = %v", r)
+	}
+	if ocr, _ := r["ocr"].(map[string]any); ocr["chars"] != float64(12) {
+		t.Errorf("grouped attrs = %v", r["ocr"])
+	}
+}
+
+func TestProcessScreenshotLogsJob(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	buf := captureLogs(t, slog.LevelDebug)
+
+	shot := writeTestPNG(t, t.TempDir(), "Screenshot 2025-01-15 at 12.30.45.png", false)
+	claude, _ := fakeCLI(t, "slack-chat")
+	result := ProcessScreenshot(Config{Provider: ProviderClaude, ClaudePath: claude, MaxFileNameLen: 80, OCRPolicy: OCRPolicyNever}, shot)
+	if !result.Success || result.JobID == 0 {
+		t.Fatalf("result = %+v", result)
+	}
+
+	records := logRecords(t, buf)
+	if len(records) == 0 {
+		t.Fatal("ProcessScreenshot should log")
+	}
+	for _, r := range records {
+		if r["component"] == "renamer" && (r["job"] != float64(result.JobID) || r["path"] != shot) {
+			t.Errorf("renamer record without job/path: %v", r)
+		}
+	}
+}
+
+func TestSetupLogging(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	prev := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	closer := setupLogging(Config{LogLevel: "warn", LogFormat: LogFormatJSON})
+	watcherLog.Info("숨김")
+	watcherLog.Warn("기록", "dir", "/shots")
+	closer.Close()
+
+	data, err := os.ReadFile(logPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := logRecords(t, bytes.NewBuffer(data))
+	if len(records) != 1 || records[0]["msg"] != "기록" || records[0]["component"] != "watcher" || records[0]["level"] != "WARN" {
+		t.Errorf("log file = %s", data)
+	}
+}
+
+func TestSetupLoggingWithoutFile(t *testing.T) {
+	// 설정 디렉토리를 만들 수 없으면 stderr에만 쓴다
+	home := filepath.Join(t.TempDir(), "home")
+	os.WriteFile(home, nil, 0644)
+	t.Setenv("HOME", home)
+	prev := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	closer := setupLogging(Config{})
+	if _, ok := closer.(noLogFile); !ok {
+		t.Errorf("setupLogging() = %T, want noLogFile", closer)
+	}
+	if err := closer.Close(); err != nil {
+		t.Errorf("Close() = %v", err)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := t.TempDir() + "/app.log"
+	f, err := openRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"aaaaaa\n", "bbbbbb\n", "cccccc\n", "dddddd\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.Close()
+
+	want := map[string]string{
+		path:        "dddddd\n",
+		path + ".1": "cccccc\n",
+		path + ".2": "bbbbbb\n",
+	}
+	for p, content := range want {
+		if got, _ := os.ReadFile(p); string(got) != content {
+			t.Errorf("%s = %q, want %q", p, got, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("only 2 backups should be kept")
+	}
+
+	// 다시 열면 기존 크기부터 이어서 센다
+	f, err = openRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("eeeeee\n"))
+	f.Close()
+	if got, _ := os.ReadFile(path + ".1"); string(got) != "dddddd\n" {
+		t.Errorf("reopened file should rotate by its existing size: %q", got)
+	}
+}
+
+func TestRotatingFilePermissions(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	path := filepath.Join(dir, "app.log")
+	// 이전 버전이 만든 0755 디렉토리와 0644 로그
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(path, []byte("old\n"), 0644)
+
+	f, err := openRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("aaaaaaaaaa\n"))
+	f.Close()
+
+	if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("log dir = %v, %v, want 0700", info, err)
+	}
+	// 회전한 뒤 새로 만든 파일과 밀려난 파일 모두 0600이다
+	for _, p := range []string{path, path + ".1"} {
+		if info, err := os.Stat(p); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("%s = %v, %v, want 0600", p, info, err)
+		}
+	}
+}
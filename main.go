@@ -3,6 +3,8 @@ package main
 import (
 	_ "embed"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
@@ -17,17 +19,30 @@ var iconData []byte
 var (
 	cfg     *Config
 	cfgLock sync.Mutex
-	watcher *Watcher
+	watcher FileWatcher
+	// 종료할 때 닫는 로그 파일
+	logFile io.Closer
+
+	// 마지막 리네이밍 결과 (다른 후보로 바꾸기용)
+	last     RenameResult
+	lastLock sync.Mutex
 )
 
 func main() {
+	// 로그 수준과 형식이 설정에 있으므로 설정을 먼저 읽고, 다른 일보다 먼저 로그를 연다
+	loaded := LoadConfig()
+	logFile = setupLogging(loaded)
+	if len(os.Args) > 1 {
+		code := runCLI(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+		// os.Exit는 defer를 실행하지 않으므로 직접 닫는다
+		logFile.Close()
+		os.Exit(code)
+	}
+	cfg = &loaded
 	systray.Run(onReady, onExit)
 }
 
 func onReady() {
-	loaded := LoadConfig()
-	cfg = &loaded
-
 	systray.SetIcon(iconData)
 	systray.SetTooltip("Auto Naming Capture")
 
@@ -36,11 +51,25 @@ func onReady() {
 	mProvider := systray.AddMenuItem("Provider", "AI Provider")
 	mClaude := mProvider.AddSubMenuItem("  Claude", "Use Claude CLI")
 	mCodex := mProvider.AddSubMenuItem("  Codex", "Use OpenAI Codex CLI")
+	mDirs := systray.AddMenuItem("Directories", "Watched directories")
+	for _, d := range cfg.Dirs() {
+		addDirMenu(mDirs, d)
+	}
 	systray.AddSeparator()
 	mLast := systray.AddMenuItem("Last: (none)", "Last renamed file")
 	mLast.Disable()
+	mAlternatives := systray.AddMenuItem("Other Names", "Switch to another candidate")
+	altItems := make([]*systray.MenuItem, maxNameCandidates-1)
+	for i := range altItems {
+		altItems[i] = mAlternatives.AddSubMenuItem("", "Rename to this candidate")
+		addAlternativeMenu(mLast, mAlternatives, altItems, i)
+	}
+	mAlternatives.Hide()
+	pending := newPendingMenu()
+	pending.refresh()
 	systray.AddSeparator()
 	mOpenFolder := systray.AddMenuItem("Open Screenshot Folder", "Open in Finder")
+	mOpenLog := systray.AddMenuItem("Open Log", "Open the log file")
 	systray.AddSeparator()
 	mAbout := systray.AddMenuItem("About", "About Auto Naming Capture")
 	mQuit := systray.AddMenuItem("Quit", "Quit the application")
@@ -49,22 +78,50 @@ func onReady() {
 	updateEnabledMenu(mEnabled, cfg.Enabled)
 	updateProviderMenu(mClaude, mCodex, cfg.Provider)
 
-	// Watcher 시작
-	var err error
-	watcher, err = NewWatcher(cfg, &cfgLock, func(result RenameResult) {
-		if result.Success {
+	// 마지막 결과를 메뉴에 표시 (감시자와 알림 액션이 함께 쓴다)
+	showLast := func(result RenameResult) {
+		lastLock.Lock()
+		defer lastLock.Unlock()
+		last = result
+		if result.Deleted {
+			mLast.SetTitle("Last: duplicate deleted")
+		} else if result.Undone {
+			mLast.SetTitle(fmt.Sprintf("Last: undone - %s", filepath.Base(result.OriginalPath)))
+		} else if result.Skipped {
+			mLast.SetTitle("Last: skipped (local only)")
+		} else if result.Success {
 			mLast.SetTitle(fmt.Sprintf("Last: %s", filepath.Base(result.NewPath)))
 		} else if result.Error != nil {
 			mLast.SetTitle(fmt.Sprintf("Last: error - %s", result.Error))
 		}
-	})
+		updateAlternativesMenu(mAlternatives, altItems, result)
+		if result.PendingID != 0 {
+			mLast.SetTitle(fmt.Sprintf("Last: pending - %s", result.SuggestedName))
+			pending.refresh()
+		}
+	}
+
+	// Watcher 시작
+	var notifier *Notifier
+	var err error
+	onRenamed := func(result RenameResult) {
+		showLast(result)
+		notifier.Notify(result)
+	}
+	watcher, err = NewFileWatcher(cfg, &cfgLock, onRenamed)
 	if err != nil {
-		fmt.Printf("Failed to create watcher: %v\n", err)
+		menuLog.Error("감시자 생성 실패", "err", err)
 		return
 	}
+	// 메뉴에서 승인한 결과도 감시자가 처리한 결과와 똑같이 보여주고 수정을 추적한다
+	pending.connect(watcher, onRenamed)
+	notifier = NewNotifier(cfg, &cfgLock, func(result RenameResult) {
+		watcher.Track(result)
+		showLast(result)
+	}, watcher.Ignore)
 
 	if err := watcher.Start(); err != nil {
-		fmt.Printf("Failed to start watcher: %v\n", err)
+		menuLog.Error("감시 시작 실패", "err", err)
 	}
 
 	go func() {
@@ -92,7 +149,13 @@ func onReady() {
 				cfgLock.Unlock()
 
 			case <-mOpenFolder.ClickedCh:
-				openFolder(cfg.ScreenshotDir)
+				cfgLock.Lock()
+				dir := expandHome(cfg.Dirs()[0].Path)
+				cfgLock.Unlock()
+				openFolder(dir)
+
+			case <-mOpenLog.ClickedCh:
+				openLogFile()
 
 			case <-mAbout.ClickedCh:
 				showAbout()
@@ -108,7 +171,206 @@ func onExit() {
 	if watcher != nil {
 		watcher.Stop()
 	}
-	fmt.Println("Auto Naming Capture 종료")
+	menuLog.Info("Auto Naming Capture 종료")
+	logFile.Close()
+}
+
+// addDirMenu는 감시 디렉토리별 켜기/끄기 메뉴를 추가한다.
+func addDirMenu(parent *systray.MenuItem, d WatchDir) {
+	m := parent.AddSubMenuItem("", d.Path)
+	updateDirMenu(m, d.Path, d.IsEnabled())
+
+	go func() {
+		for range m.ClickedCh {
+			cfgLock.Lock()
+			enabled := true
+			for _, cur := range cfg.Dirs() {
+				if cur.Path == d.Path {
+					enabled = !cur.IsEnabled()
+				}
+			}
+			cfg.SetDirEnabled(d.Path, enabled)
+			updateDirMenu(m, d.Path, enabled)
+			SaveConfig(*cfg)
+			cfgLock.Unlock()
+		}
+	}()
+}
+
+// 메뉴에 보여주는 승인 대기 항목 수 (나머지는 pending 명령으로 처리)
+const maxPendingMenuItems = 10
+
+// pendingMenu는 "N pending" 메뉴와 항목별 승인/수정/거절 하위 메뉴다.
+type pendingMenu struct {
+	parent *systray.MenuItem
+	slots  []pendingSlot
+
+	mu    sync.Mutex
+	items []PendingItem
+	// 승인할 때 쓰는 감시자와 결과 처리 (감시자를 만든 뒤 연결)
+	watcher   FileWatcher
+	onRenamed func(RenameResult)
+}
+
+type pendingSlot struct {
+	item, approve, edit, reject *systray.MenuItem
+}
+
+func newPendingMenu() *pendingMenu {
+	m := &pendingMenu{parent: systray.AddMenuItem("0 pending", "Renames waiting for approval")}
+	for i := 0; i < maxPendingMenuItems; i++ {
+		item := m.parent.AddSubMenuItem("", "")
+		slot := pendingSlot{
+			item:    item,
+			approve: item.AddSubMenuItem("Approve", "Rename with the suggested name"),
+			edit:    item.AddSubMenuItem("Edit…", "Edit the name, then rename"),
+			reject:  item.AddSubMenuItem("Reject", "Keep the original name"),
+		}
+		m.slots = append(m.slots, slot)
+		go m.handle(i, slot)
+	}
+	m.parent.Hide()
+	return m
+}
+
+// refresh는 저장된 대기열을 다시 읽어 메뉴에 반영한다 (재시작 후에도 남아 있다).
+func (m *pendingMenu) refresh() {
+	items, err := ListPending()
+	if err != nil {
+		menuLog.Error("승인 대기열 읽기 실패", "err", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items = items
+
+	if len(items) == 0 {
+		m.parent.Hide()
+		return
+	}
+	m.parent.SetTitle(fmt.Sprintf("%d pending", len(items)))
+	for i, slot := range m.slots {
+		if i < len(items) {
+			slot.item.SetTitle(fmt.Sprintf("%s ← %s", items[i].Name, filepath.Base(items[i].Path)))
+			slot.item.Show()
+		} else {
+			slot.item.Hide()
+		}
+	}
+	m.parent.Show()
+}
+
+func (m *pendingMenu) connect(w FileWatcher, onRenamed func(RenameResult)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.watcher, m.onRenamed = w, onRenamed
+}
+
+func (m *pendingMenu) handle(i int, slot pendingSlot) {
+	for {
+		var action string
+		select {
+		case <-slot.approve.ClickedCh:
+			action = "approve"
+		case <-slot.edit.ClickedCh:
+			action = "edit"
+		case <-slot.reject.ClickedCh:
+			action = "reject"
+		}
+
+		m.mu.Lock()
+		if i >= len(m.items) {
+			m.mu.Unlock()
+			continue
+		}
+		item := m.items[i]
+		w, onRenamed := m.watcher, m.onRenamed
+		m.mu.Unlock()
+
+		switch action {
+		case "reject":
+			if err := RejectPending(item.ID); err != nil {
+				menuLog.Error("거절 실패", "id", item.ID, "err", err)
+			}
+		case "approve", "edit":
+			var name string
+			if action == "edit" {
+				var ok bool
+				if name, ok = askPendingName(item); !ok {
+					continue
+				}
+			}
+			cfgLock.Lock()
+			snapshot := *cfg
+			cfgLock.Unlock()
+			var ignore func(path string)
+			if w != nil {
+				ignore = w.Ignore
+			}
+			result := ApprovePending(snapshot, item.ID, name, ignore)
+			if result.Error != nil {
+				menuLog.Error("승인 실패", "id", item.ID, "err", result.Error)
+				break
+			}
+			if w != nil {
+				w.Track(result)
+				onRenamed(result)
+			}
+		}
+		m.refresh()
+	}
+}
+
+// addAlternativeMenu는 마지막 결과의 i번째 다른 후보로 바꾸는 메뉴 동작을 연결한다.
+func addAlternativeMenu(mLast, parent *systray.MenuItem, items []*systray.MenuItem, i int) {
+	go func() {
+		for range items[i].ClickedCh {
+			lastLock.Lock()
+			if i >= len(last.Alternatives) {
+				lastLock.Unlock()
+				continue
+			}
+			cfgLock.Lock()
+			d, _ := cfg.DirFor(last.OriginalPath)
+			snapshot := cfg.ForDir(d)
+			cfgLock.Unlock()
+
+			switched := SwitchName(snapshot, last, last.Alternatives[i], watcher.Ignore)
+			if switched.Error != nil {
+				menuLog.Error("후보 변경 실패", "job", last.JobID, "path", last.OriginalPath, "err", switched.Error)
+			} else {
+				watcher.Track(switched)
+				last = switched
+				mLast.SetTitle(fmt.Sprintf("Last: %s", filepath.Base(last.NewPath)))
+			}
+			updateAlternativesMenu(parent, items, last)
+			lastLock.Unlock()
+		}
+	}()
+}
+
+// updateAlternativesMenu는 다른 후보가 있으면 하위 메뉴에 보여주고, 없으면 숨긴다.
+func updateAlternativesMenu(parent *systray.MenuItem, items []*systray.MenuItem, result RenameResult) {
+	if !result.Success || result.Deleted || len(result.Alternatives) == 0 {
+		parent.Hide()
+		return
+	}
+	for i, item := range items {
+		if i < len(result.Alternatives) {
+			item.SetTitle(result.Alternatives[i])
+			item.Show()
+		} else {
+			item.Hide()
+		}
+	}
+	parent.Show()
+}
+
+func updateDirMenu(m *systray.MenuItem, path string, enabled bool) {
+	if enabled {
+		m.SetTitle("✓ " + path)
+	} else {
+		m.SetTitle("  " + path)
+	}
 }
 
 func updateEnabledMenu(m *systray.MenuItem, enabled bool) {
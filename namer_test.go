@@ -1,6 +1,7 @@
 package main
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -132,7 +133,7 @@ func TestTruncate(t *testing.T) {
 
 func TestBuildPrompt(t *testing.T) {
 	t.Run("with OCR text", func(t *testing.T) {
-		result := buildPrompt("/path/to/image.png", OCRResult{Text: "Hello World", HasText: true})
+		result := buildPrompt(promptDataFor("/path/to/image.png", OCRResult{Text: "Hello World", HasText: true}))
 
 		if !strings.Contains(result, "/path/to/image.png") {
 			t.Error("prompt should contain image path")
@@ -146,7 +147,7 @@ func TestBuildPrompt(t *testing.T) {
 	})
 
 	t.Run("without OCR text", func(t *testing.T) {
-		result := buildPrompt("/path/to/image.png", OCRResult{HasText: false})
+		result := buildPrompt(promptDataFor("/path/to/image.png", OCRResult{HasText: false}))
 
 		if !strings.Contains(result, "/path/to/image.png") {
 			t.Error("prompt should contain image path")
@@ -158,7 +159,7 @@ func TestBuildPrompt(t *testing.T) {
 
 	t.Run("long OCR text truncated", func(t *testing.T) {
 		longText := strings.Repeat("가", 1000)
-		result := buildPrompt("/img.png", OCRResult{Text: longText, HasText: true})
+		result := buildPrompt(promptDataFor("/img.png", OCRResult{Text: longText, HasText: true}))
 
 		// 500자 + "..." 이므로 원본 1000자보다 짧아야 함
 		if strings.Contains(result, longText) {
@@ -172,7 +173,7 @@ func TestBuildPrompt(t *testing.T) {
 
 func TestBuildCodexPrompt(t *testing.T) {
 	t.Run("includes system prompt", func(t *testing.T) {
-		result := buildCodexPrompt(OCRResult{HasText: false})
+		result := buildCodexPrompt(promptDataFor("/img.png", OCRResult{HasText: false}))
 
 		if !strings.Contains(result, "스크린샷 파일명 생성기") {
 			t.Error("codex prompt should include system prompt content")
@@ -183,10 +184,64 @@ func TestBuildCodexPrompt(t *testing.T) {
 	})
 
 	t.Run("with OCR text", func(t *testing.T) {
-		result := buildCodexPrompt(OCRResult{Text: "some text", HasText: true})
+		result := buildCodexPrompt(promptDataFor("/img.png", OCRResult{Text: "some text", HasText: true}))
 
 		if !strings.Contains(result, "참고용 OCR 텍스트") {
 			t.Error("codex prompt should contain OCR text when available")
 		}
 	})
 }
+
+func TestCandidateLines(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		n    int
+		want []string
+	}{
+		{"single keeps lines", "slack-chat\ngithub-pr", 1, []string{"slack-chat\ngithub-pr"}},
+		{"plain lines", "slack-chat\n\ngithub-pr\n", 3, []string{"slack-chat", "github-pr"}},
+		{"numbered", "1. slack-chat\n2) github-pr\n3. figma-design", 3, []string{"slack-chat", "github-pr", "figma-design"}},
+		{"bulleted", "- slack-chat\n* github-pr\n• figma", 3, []string{"slack-chat", "github-pr", "figma"}},
+		// 이름 안의 숫자는 번호로 보지 않는다
+		{"leading digits in name", "2025-report\n3d-model", 2, []string{"2025-report", "3d-model"}},
+		{"blank output", "  \n", 3, []string{"  \n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := candidateLines(tt.out, tt.n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("candidateLines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateNames(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	shot := writeTestPNG(t, t.TempDir(), "shot.png", false)
+
+	tests := []struct {
+		name       string
+		output     string
+		candidates int
+		want       []string
+	}{
+		{"single", "slack-chat", 1, []string{"slack-chat"}},
+		{"three", "1. slack-chat\n2. slack-배포-논의\n3. slack-dm", 3, []string{"slack-chat", "slack-배포-논의", "slack-dm"}},
+		{"drops invalid and duplicate", "slack-chat\nI cannot help with that request.\nslack chat\ngithub-pr", 3, []string{"slack-chat", "github-pr"}},
+		{"trims extra", "a-b\nc-d\ne-f\ng-h", 2, []string{"a-b", "c-d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claude, _ := fakeCLI(t, tt.output)
+			cfg := Config{Provider: ProviderClaude, ClaudePath: claude, MaxFileNameLen: 80, NameCandidates: tt.candidates}
+			got, err := GenerateNames(cfg, shot, newPromptData(cfg, shot, OCRResult{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GenerateNames = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
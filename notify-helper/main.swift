@@ -0,0 +1,129 @@
+import Foundation
+import UserNotifications
+
+// 알림 센터에 액션 버튼이 붙은 알림을 띄우고, 누른 버튼의 키를 한 줄로 출력한 뒤 끝난다.
+// 알림을 닫거나 --timeout이 지나면(또는 SIGTERM을 받으면) 알림을 지우고 아무것도 출력하지 않는다.
+// UNUserNotificationCenter는 번들이 있어야 하므로 NotifyHelper.app 안의 실행 파일로 호출한다.
+
+let usage = "Usage: notify-helper --title <title> [--subtitle <text>] [--body <text>] [--action key:label]... [--timeout seconds]\n"
+
+var title: String?
+var subtitle = ""
+var body = ""
+var actions: [(key: String, label: String)] = []
+var timeout: TimeInterval = 600
+
+var args = CommandLine.arguments.dropFirst()
+while let arg = args.popFirst() {
+    guard let value = args.popFirst() else {
+        fputs(usage, stderr)
+        exit(1)
+    }
+    switch arg {
+    case "--title":
+        title = value
+    case "--subtitle":
+        subtitle = value
+    case "--body":
+        body = value
+    case "--action":
+        guard let sep = value.firstIndex(of: ":") else {
+            fputs(usage, stderr)
+            exit(1)
+        }
+        actions.append((String(value[..<sep]), String(value[value.index(after: sep)...])))
+    case "--timeout":
+        guard let seconds = TimeInterval(value), seconds > 0 else {
+            fputs(usage, stderr)
+            exit(1)
+        }
+        timeout = seconds
+    default:
+        fputs(usage, stderr)
+        exit(1)
+    }
+}
+
+guard let title = title else {
+    fputs(usage, stderr)
+    exit(1)
+}
+
+let center = UNUserNotificationCenter.current()
+let requestID = UUID().uuidString
+
+// finish는 버튼이 더 이상 동작하지 않도록 알림을 지우고 끝낸다
+func finish() -> Never {
+    center.removeDeliveredNotifications(withIdentifiers: [requestID])
+    exit(0)
+}
+
+final class Delegate: NSObject, UNUserNotificationCenterDelegate {
+    // helper가 앞에 있는 앱으로 취급되어도 배너를 보여준다
+    func userNotificationCenter(_ center: UNUserNotificationCenter, willPresent notification: UNNotification,
+                                withCompletionHandler completionHandler: @escaping (UNNotificationPresentationOptions) -> Void) {
+        completionHandler([.banner, .list])
+    }
+
+    func userNotificationCenter(_ center: UNUserNotificationCenter, didReceive response: UNNotificationResponse,
+                                withCompletionHandler completionHandler: @escaping () -> Void) {
+        let key = response.actionIdentifier
+        if key != UNNotificationDefaultActionIdentifier && key != UNNotificationDismissActionIdentifier {
+            print(key)
+            fflush(stdout)
+        }
+        completionHandler()
+        exit(0)
+    }
+}
+
+let delegate = Delegate()
+center.delegate = delegate
+
+signal(SIGTERM, SIG_IGN)
+let sigterm = DispatchSource.makeSignalSource(signal: SIGTERM, queue: .main)
+sigterm.setEventHandler { finish() }
+sigterm.resume()
+
+center.requestAuthorization(options: [.alert, .sound]) { granted, error in
+    if let error = error {
+        fputs("Authorization error: \(error.localizedDescription)\n", stderr)
+        exit(1)
+    }
+    guard granted else {
+        fputs("Error: notifications are not allowed for NotifyHelper\n", stderr)
+        exit(1)
+    }
+
+    // 액션 조합마다 카테고리를 만든다 (닫기도 응답으로 받아 바로 끝낸다)
+    let categoryID = "actions." + actions.map { $0.key }.joined(separator: ".")
+    let category = UNNotificationCategory(
+        identifier: categoryID,
+        actions: actions.map { UNNotificationAction(identifier: $0.key, title: $0.label, options: []) },
+        intentIdentifiers: [],
+        options: [.customDismissAction]
+    )
+    center.setNotificationCategories([category])
+
+    let content = UNMutableNotificationContent()
+    content.title = title
+    content.subtitle = subtitle
+    content.body = body
+    content.categoryIdentifier = categoryID
+
+    center.add(UNNotificationRequest(identifier: requestID, content: content, trigger: nil)) { error in
+        if let error = error {
+            fputs("Notification error: \(error.localizedDescription)\n", stderr)
+            exit(1)
+        }
+        // 버튼이 없으면 기다릴 응답도 없다
+        if actions.isEmpty {
+            exit(0)
+        }
+    }
+}
+
+DispatchQueue.main.asyncAfter(deadline: .now() + timeout) { finish() }
+
+// 알림 응답과 시그널은 메인 큐로 전달된다
+dispatchMain()
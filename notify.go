@@ -0,0 +1,251 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// 이 간격 안에 여러 결과가 나오면 모아서 알림 하나로 보낸다
+	notifyInterval = 3 * time.Second
+	// 모은 알림 본문에 보여주는 파일 수
+	notifySummaryMax = 3
+	notifyAppName    = "Auto Naming Capture"
+	// 액션을 기억해 두는 알림 수 (넘으면 가장 오래된 알림의 액션부터 버린다)
+	maxNotificationActions = 32
+)
+
+// Notification은 OS 알림 하나다. 액션을 누르면 Run이 호출된다.
+type Notification struct {
+	Title   string
+	Body    string
+	Actions []NotificationAction
+}
+
+type NotificationAction struct {
+	Key   string
+	Label string
+	Run   func()
+}
+
+// notifyBackend는 OS별 알림 구현이다 (Linux: D-Bus, macOS: notify-helper 또는 osascript).
+type notifyBackend interface {
+	Show(n Notification) error
+}
+
+// Notifier는 리네이밍 결과를 데스크톱 알림으로 보여준다.
+// 알림 액션(되돌리기, 열기, 이름 바꾸기)으로 파일이 바뀌면 onChange를 호출한다.
+type Notifier struct {
+	cfg      *Config
+	cfgLock  *sync.Mutex
+	backend  notifyBackend
+	interval time.Duration
+	onChange func(RenameResult)
+	// 앱이 옮길 경로를 감시자에게 알린다 (되돌린 원본을 새 스크린샷으로,
+	// 이름 바꾸기를 사용자 수정으로 처리하지 않도록)
+	ignore func(path string)
+
+	mu     sync.Mutex
+	last   time.Time
+	queued []RenameResult
+	timer  *time.Timer
+}
+
+// NewNotifier는 OS 알림 백엔드를 연결한다. 알림을 쓸 수 없으면 Notify는 아무것도 하지 않는다.
+func NewNotifier(cfg *Config, cfgLock *sync.Mutex, onChange func(RenameResult), ignore func(path string)) *Notifier {
+	backend, err := newNotifyBackend()
+	if err != nil {
+		notifyLog.Warn("알림 사용 불가", "err", err)
+	}
+	return &Notifier{cfg: cfg, cfgLock: cfgLock, backend: backend, interval: notifyInterval, onChange: onChange, ignore: ignore}
+}
+
+// Notify는 결과를 알린다. 직전 알림 후 interval이 지나지 않았으면 모아뒀다가 한 번에 알린다.
+func (n *Notifier) Notify(result RenameResult) {
+	if n == nil || n.backend == nil || result.Skipped {
+		return
+	}
+	n.cfgLock.Lock()
+	enabled := n.cfg.NotificationsEnabled()
+	n.cfgLock.Unlock()
+	if !enabled {
+		return
+	}
+
+	n.mu.Lock()
+	now := time.Now()
+	if n.timer == nil && now.Sub(n.last) >= n.interval {
+		n.last = now
+		n.mu.Unlock()
+		n.show(n.notification(result))
+		return
+	}
+	n.queued = append(n.queued, result)
+	if n.timer == nil {
+		n.timer = time.AfterFunc(n.interval-now.Sub(n.last), n.flush)
+	}
+	n.mu.Unlock()
+}
+
+func (n *Notifier) flush() {
+	n.mu.Lock()
+	queued := n.queued
+	n.queued, n.timer, n.last = nil, nil, time.Now()
+	n.mu.Unlock()
+
+	switch len(queued) {
+	case 0:
+	case 1:
+		n.show(n.notification(queued[0]))
+	default:
+		n.show(summaryNotification(queued))
+	}
+}
+
+func (n *Notifier) show(notification Notification) {
+	if err := n.backend.Show(notification); err != nil {
+		notifyLog.Error("알림 실패", "title", notification.Title, "err", err)
+	}
+}
+
+// notification은 결과 하나에 대한 알림이다. 리네이밍에 성공했으면 액션을 붙인다.
+func (n *Notifier) notification(result RenameResult) Notification {
+	switch {
+	case result.Error != nil:
+		return Notification{
+			Title: "리네이밍 실패",
+			Body:  fmt.Sprintf("%s: %v", filepath.Base(result.OriginalPath), result.Error),
+			Actions: []NotificationAction{
+				{Key: "open", Label: "Open", Run: func() { revealFile(result.OriginalPath) }},
+			},
+		}
+	case result.Deleted:
+		return Notification{Title: "중복 스크린샷 삭제", Body: filepath.Base(result.OriginalPath)}
+	case result.PendingID != 0:
+		return Notification{
+			Title: fmt.Sprintf("승인 대기 #%d", result.PendingID),
+			Body:  fmt.Sprintf("%s ← %s", result.SuggestedName, filepath.Base(result.OriginalPath)),
+		}
+	}
+
+	// 액션을 차례로 누를 수 있도록 현재 상태를 같이 갱신한다
+	var mu sync.Mutex
+	cur := result
+	apply := func(action string, f func(RenameResult) RenameResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		next := f(cur)
+		if next.Error != nil {
+			notifyLog.Error("알림 액션 실패", "action", action, "job", cur.JobID, "path", cur.OriginalPath, "err", next.Error)
+			return
+		}
+		cur = next
+		if n.onChange != nil {
+			n.onChange(cur)
+		}
+	}
+	return Notification{
+		Title: "스크린샷 이름 변경",
+		Body:  filepath.Base(result.NewPath),
+		Actions: []NotificationAction{
+			{Key: "undo", Label: "Undo", Run: func() {
+				apply("undo", func(r RenameResult) RenameResult {
+					if n.ignore != nil {
+						n.ignore(r.OriginalPath)
+					}
+					return UndoRename(r)
+				})
+			}},
+			{Key: "open", Label: "Open", Run: func() {
+				mu.Lock()
+				path := cur.NewPath
+				mu.Unlock()
+				revealFile(path)
+			}},
+			{Key: "rename", Label: "Rename", Run: func() {
+				apply("rename", func(r RenameResult) RenameResult {
+					name, ok := askName(filepath.Base(r.NewPath), r.SuggestedName)
+					if !ok {
+						r.Error = errors.New("canceled")
+						return r
+					}
+					return RenameTo(n.snapshot(r.OriginalPath), r, name, n.ignore)
+				})
+			}},
+		},
+	}
+}
+
+// snapshot은 path가 속한 감시 디렉토리의 설정을 lock 하에 복사한다.
+func (n *Notifier) snapshot(path string) Config {
+	n.cfgLock.Lock()
+	defer n.cfgLock.Unlock()
+	d, _ := n.cfg.DirFor(path)
+	return n.cfg.ForDir(d)
+}
+
+// summaryNotification은 짧은 시간에 쏟아진 결과를 하나로 묶은 알림이다 (액션 없음).
+func summaryNotification(results []RenameResult) Notification {
+	var names []string
+	failed := 0
+	for _, r := range results {
+		switch {
+		case r.Error != nil:
+			failed++
+		case r.Success && !r.Deleted:
+			names = append(names, filepath.Base(r.NewPath))
+		}
+	}
+	title := fmt.Sprintf("스크린샷 %d개 처리", len(results))
+	if failed > 0 {
+		title += fmt.Sprintf(" (실패 %d개)", failed)
+	}
+	body := strings.Join(names[:min(len(names), notifySummaryMax)], "\n")
+	if len(names) > notifySummaryMax {
+		body += fmt.Sprintf("\n외 %d개", len(names)-notifySummaryMax)
+	}
+	return Notification{Title: title, Body: body}
+}
+
+// revealFile은 파일 관리자에서 파일(없으면 그 디렉토리)을 보여준다.
+func revealFile(path string) {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := os.Stat(path); err == nil {
+			exec.Command("open", "-R", path).Start()
+		} else {
+			exec.Command("open", filepath.Dir(path)).Start()
+		}
+	case "linux":
+		exec.Command("xdg-open", filepath.Dir(path)).Start()
+	}
+}
+
+// askName은 새 이름을 입력받는 창을 띄운다 (macOS: osascript, Linux: zenity).
+// 입력받을 방법이 없거나 취소하면 false를 반환한다.
+var askName = func(title, current string) (string, bool) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf(`text returned of (display dialog "%s\n\n새 이름:" with title "%s" default answer "%s" buttons {"취소", "확인"} default button "확인" cancel button "취소")`,
+			escapeAppleScript(title), notifyAppName, escapeAppleScript(current))
+		cmd = exec.Command("osascript", "-e", script)
+	case "linux":
+		cmd = exec.Command("zenity", "--entry", "--title", notifyAppName, "--text", title+"\n\n새 이름:", "--entry-text", current)
+	default:
+		return "", false
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", false
+	}
+	name := strings.TrimSpace(string(out))
+	return name, name != ""
+}
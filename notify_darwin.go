@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// notify-helper 앱 번들 안의 실행 파일 (make build-notify로 만든다)
+var notifyHelperRel = filepath.Join("notify-helper", "NotifyHelper.app", "Contents", "MacOS", "notify-helper")
+
+// 알림 센터에 알림을 띄운다. notify-helper가 있으면 UNUserNotificationCenter로 버튼이 붙은
+// 알림을, 없으면 osascript로 버튼 없는 알림을 보낸다.
+func newNotifyBackend() (notifyBackend, error) {
+	if path := notifyHelperPath(); path != "" {
+		return &helperNotifier{path: path}, nil
+	}
+	if _, err := exec.LookPath("osascript"); err != nil {
+		return nil, err
+	}
+	notifyLog.Warn("notify-helper 없음 - 알림 버튼 없이 표시", "path", notifyHelperRel)
+	return osascriptNotifier{}, nil
+}
+
+// notifyHelperPath는 바이너리와 같은 디렉토리, 현재 디렉토리 순으로 notify-helper를 찾는다.
+func notifyHelperPath() string {
+	execPath, _ := os.Executable()
+	for _, dir := range []string{filepath.Dir(execPath), "."} {
+		path := filepath.Join(dir, notifyHelperRel)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// osascriptNotifier는 osascript 알림을 보낸다. 버튼을 붙일 수 없으므로 액션은 메뉴로만 쓸 수 있다.
+type osascriptNotifier struct{}
+
+func (osascriptNotifier) Show(n Notification) error {
+	script := fmt.Sprintf(`display notification "%s" with title "%s" subtitle "%s"`,
+		escapeAppleScript(n.Body), notifyAppName, escapeAppleScript(n.Title))
+	return exec.Command("osascript", "-e", script).Run()
+}
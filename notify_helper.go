@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// 알림 버튼을 누를 수 있는 시간. 지나면 helper가 알림을 지우고 끝난다.
+const notifyActionTimeout = 10 * time.Minute
+
+// helperNotifier는 알림 하나마다 helper 프로세스(macOS의 notify-helper)를 띄운다.
+// helper는 알림을 보여주고, 누른 액션의 키를 한 줄 출력한 뒤 끝난다.
+type helperNotifier struct {
+	path string
+
+	mu sync.Mutex
+	// 응답을 기다리는 helper (최대 maxNotificationActions개)
+	running []*helperProcess
+}
+
+type helperProcess struct {
+	cmd *exec.Cmd
+	// 개수 제한으로 일부러 끝낸 helper (실패로 기록하지 않는다)
+	evicted bool
+}
+
+func (h *helperNotifier) Show(n Notification) error {
+	args := []string{"--title", notifyAppName, "--subtitle", n.Title, "--body", n.Body,
+		"--timeout", strconv.Itoa(int(notifyActionTimeout / time.Second))}
+	for _, a := range n.Actions {
+		args = append(args, "--action", a.Key+":"+a.Label)
+	}
+	cmd := exec.Command(h.path, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	p := &helperProcess{cmd: cmd}
+	h.add(p)
+
+	go func() {
+		key, _ := bufio.NewReader(stdout).ReadString('\n')
+		err := cmd.Wait()
+		if h.remove(p) {
+			return
+		}
+		if err != nil {
+			notifyLog.Error("알림 실패", "title", n.Title, "err", err, "stderr", strings.TrimSpace(stderr.String()))
+			return
+		}
+		key = strings.TrimSpace(key)
+		for _, a := range n.Actions {
+			if a.Key == key {
+				a.Run()
+			}
+		}
+	}()
+	return nil
+}
+
+// add는 helper를 기록하고, 너무 많으면 가장 오래된 helper를 끝내 알림을 지운다.
+func (h *helperNotifier) add(p *helperProcess) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for len(h.running) >= maxNotificationActions {
+		oldest := h.running[0]
+		oldest.evicted = true
+		oldest.cmd.Process.Signal(syscall.SIGTERM)
+		h.running = h.running[1:]
+	}
+	h.running = append(h.running, p)
+}
+
+// remove는 끝난 helper를 지우고, 개수 제한으로 끝낸 helper인지 반환한다.
+func (h *helperNotifier) remove(p *helperProcess) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.running = slices.DeleteFunc(h.running, func(r *helperProcess) bool { return r == p })
+	return p.evicted
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHelperNotifier(t *testing.T) {
+	bin, argsFile := fakeCLI(t, "undo")
+	h := &helperNotifier{path: bin}
+
+	ran := make(chan string, 2)
+	err := h.Show(Notification{
+		Title: "스크린샷 이름 변경",
+		Body:  "2025-01-15_slack-chat.png",
+		Actions: []NotificationAction{
+			{Key: "undo", Label: "Undo", Run: func() { ran <- "undo" }},
+			{Key: "open", Label: "Open", Run: func() { ran <- "open" }},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case key := <-ran:
+		if key != "undo" {
+			t.Errorf("ran %q, want undo", key)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("action was not run")
+	}
+
+	want := []string{"--title", notifyAppName, "--subtitle", "스크린샷 이름 변경", "--body", "2025-01-15_slack-chat.png",
+		"--timeout", "600", "--action", "undo:Undo", "--action", "open:Open"}
+	if got := readArgs(t, argsFile); !slices.Equal(got, want) {
+		t.Errorf("helper args = %q, want %q", got, want)
+	}
+}
+
+// syncBuffer는 helper를 기다리는 고루틴이 쓰는 로그를 테스트에서 안전하게 읽게 한다.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestHelperNotifierBounded(t *testing.T) {
+	var logs syncBuffer
+	prev := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	bin, _ := fakeCLI(t, "")
+	// 응답 없이 기다리다 SIGTERM을 받으면 실패 코드로 끝나는 helper
+	os.WriteFile(bin, []byte("#!/bin/sh\ntrap 'exit 1' TERM\nwhile :; do sleep 0.1; done\n"), 0755)
+	h := &helperNotifier{path: bin}
+	t.Cleanup(func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		for _, p := range h.running {
+			p.cmd.Process.Signal(syscall.SIGKILL)
+		}
+	})
+
+	var first *exec.Cmd
+	for i := range maxNotificationActions + 1 {
+		if err := h.Show(Notification{Title: "t", Actions: []NotificationAction{{Key: "open", Label: "Open", Run: func() {}}}}); err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			first = h.running[0].cmd
+		}
+	}
+	h.mu.Lock()
+	count := len(h.running)
+	h.mu.Unlock()
+	if count != maxNotificationActions {
+		t.Errorf("running %d helpers, want %d", count, maxNotificationActions)
+	}
+
+	// 가장 오래된 helper는 SIGTERM을 받고 끝난다
+	deadline := time.Now().Add(2 * time.Second)
+	for first.Process.Signal(syscall.Signal(0)) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("oldest helper was not terminated")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	// 일부러 끝낸 helper는 실패로 기록하지 않는다
+	time.Sleep(100 * time.Millisecond)
+	if strings.Contains(logs.String(), "알림 실패") {
+		t.Errorf("evicted helper logged as a failure: %s", logs.String())
+	}
+
+	// 스스로 실패한 helper는 기록한다
+	os.WriteFile(bin, []byte("#!/bin/sh\necho denied >&2\nexit 1\n"), 0755)
+	h.Show(Notification{Title: "t"})
+	deadline = time.Now().Add(2 * time.Second)
+	for !strings.Contains(logs.String(), "알림 실패") {
+		if time.Now().After(deadline) {
+			t.Fatal("failed helper was not logged")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	dbusNotifyPath      = "/org/freedesktop/Notifications"
+	dbusNotifyInterface = "org.freedesktop.Notifications"
+)
+
+// dbusNotifier는 org.freedesktop.Notifications 서비스로 알림을 보내고,
+// ActionInvoked 시그널을 받아 해당 알림의 액션을 실행한다.
+type dbusNotifier struct {
+	conn *dbus.Conn
+
+	mu sync.Mutex
+	// 알림 ID별 액션 (액션을 실행하거나 알림이 닫히면 지우고, 최대 maxNotificationActions개)
+	actions map[uint32][]NotificationAction
+}
+
+func newNotifyBackend() (notifyBackend, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("connect session bus: %w", err)
+	}
+	n, err := newDBusNotifier(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return n, nil
+}
+
+func newDBusNotifier(conn *dbus.Conn) (*dbusNotifier, error) {
+	err := conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(dbusNotifyPath),
+		dbus.WithMatchInterface(dbusNotifyInterface),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("subscribe notification signals: %w", err)
+	}
+	n := &dbusNotifier{conn: conn, actions: make(map[uint32][]NotificationAction)}
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	go n.listen(signals)
+	return n, nil
+}
+
+func (n *dbusNotifier) Show(notification Notification) error {
+	// 액션은 [키, 라벨, 키, 라벨, ...] 형식으로 보낸다
+	var actions []string
+	for _, a := range notification.Actions {
+		actions = append(actions, a.Key, a.Label)
+	}
+
+	// 시그널이 ID 등록보다 먼저 처리되지 않도록 호출하는 동안 잠근다
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var id uint32
+	call := n.conn.Object(dbusNotifyInterface, dbusNotifyPath).Call(dbusNotifyInterface+".Notify", 0,
+		notifyAppName, uint32(0), "", notification.Title, notification.Body, actions, map[string]dbus.Variant{}, int32(-1))
+	if err := call.Store(&id); err != nil {
+		return err
+	}
+	if len(notification.Actions) > 0 {
+		// NotificationClosed를 보내지 않는 서버도 있으므로 가장 오래된(ID가 가장 작은) 알림부터 버린다
+		for len(n.actions) >= maxNotificationActions {
+			delete(n.actions, slices.Min(slices.Collect(maps.Keys(n.actions))))
+		}
+		n.actions[id] = notification.Actions
+	}
+	return nil
+}
+
+func (n *dbusNotifier) listen(signals <-chan *dbus.Signal) {
+	for sig := range signals {
+		if len(sig.Body) < 2 {
+			continue
+		}
+		id, ok := sig.Body[0].(uint32)
+		if !ok {
+			continue
+		}
+		switch sig.Name {
+		case dbusNotifyInterface + ".ActionInvoked":
+			key, _ := sig.Body[1].(string)
+			// 알림 하나의 액션은 한 번만 실행한다
+			n.mu.Lock()
+			actions := n.actions[id]
+			delete(n.actions, id)
+			n.mu.Unlock()
+			for _, a := range actions {
+				if a.Key == key {
+					// 이름 입력 창처럼 오래 걸리는 액션이 다른 시그널을 막지 않게 한다
+					go a.Run()
+				}
+			}
+		case dbusNotifyInterface + ".NotificationClosed":
+			n.mu.Lock()
+			delete(n.actions, id)
+			n.mu.Unlock()
+		}
+	}
+}
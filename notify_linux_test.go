@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeNotifications는 org.freedesktop.Notifications 서비스를 흉내 낸다.
+type fakeNotifications struct {
+	mu    sync.Mutex
+	calls []fakeNotifyCall
+}
+
+type fakeNotifyCall struct {
+	appName, summary, body string
+	actions                []string
+}
+
+func (f *fakeNotifications) Notify(appName string, replacesID uint32, icon, summary, body string, actions []string, hints map[string]dbus.Variant, timeout int32) (uint32, *dbus.Error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, fakeNotifyCall{appName, summary, body, actions})
+	return uint32(len(f.calls)), nil
+}
+
+// startSessionBus는 테스트 전용 세션 버스를 띄우고 주소를 반환한다.
+func startSessionBus(t *testing.T) string {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+	cmd := exec.Command(daemon, "--session", "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	addr, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("read bus address: %v", err)
+	}
+	return strings.TrimSpace(addr)
+}
+
+func TestDBusNotifier(t *testing.T) {
+	addr := startSessionBus(t)
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", addr)
+
+	// 알림 서버 역할을 하는 연결
+	server, err := dbus.Connect(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	fake := &fakeNotifications{}
+	if err := server.Export(fake, dbusNotifyPath, dbusNotifyInterface); err != nil {
+		t.Fatal(err)
+	}
+	if reply, err := server.RequestName(dbusNotifyInterface, dbus.NameFlagDoNotQueue); err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("RequestName = %v, %v", reply, err)
+	}
+
+	backend, err := newNotifyBackend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ran := make(chan string, 2)
+	err = backend.Show(Notification{
+		Title: "스크린샷 이름 변경",
+		Body:  "2025-01-15_slack-chat.png",
+		Actions: []NotificationAction{
+			{Key: "undo", Label: "Undo", Run: func() { ran <- "undo" }},
+			{Key: "open", Label: "Open", Run: func() { ran <- "open" }},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake.mu.Lock()
+	calls := fake.calls
+	fake.mu.Unlock()
+	if len(calls) != 1 {
+		t.Fatalf("Notify calls = %+v", calls)
+	}
+	if got := calls[0]; got.appName != notifyAppName || got.summary != "스크린샷 이름 변경" || got.body != "2025-01-15_slack-chat.png" ||
+		strings.Join(got.actions, ",") != "undo,Undo,open,Open" {
+		t.Errorf("Notify call = %+v", got)
+	}
+
+	emit := func(member string, body ...any) {
+		if err := server.Emit(dbusNotifyPath, dbusNotifyInterface+"."+member, body...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	emit("ActionInvoked", uint32(1), "open")
+	select {
+	case key := <-ran:
+		if key != "open" {
+			t.Errorf("ran %q, want open", key)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("action was not run")
+	}
+
+	// 실행한 알림의 액션은 다시 실행하지 않는다
+	emit("ActionInvoked", uint32(1), "undo")
+	select {
+	case key := <-ran:
+		t.Errorf("action %q ran after another action of the same notification", key)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// 닫힌 알림의 액션은 더 이상 실행하지 않는다
+	backend.Show(Notification{Title: "t", Actions: []NotificationAction{{Key: "undo", Label: "Undo", Run: func() { ran <- "undo" }}}})
+	emit("NotificationClosed", uint32(2), uint32(2))
+	emit("ActionInvoked", uint32(2), "undo")
+	select {
+	case key := <-ran:
+		t.Errorf("action %q ran after the notification closed", key)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// 닫힘 시그널이 오지 않아도 기억하는 액션 수는 제한된다
+	n := backend.(*dbusNotifier)
+	for range maxNotificationActions + 5 {
+		backend.Show(Notification{Title: "t", Actions: []NotificationAction{{Key: "open", Label: "Open", Run: func() {}}}})
+	}
+	n.mu.Lock()
+	count := len(n.actions)
+	_, oldest := n.actions[3]
+	n.mu.Unlock()
+	if count != maxNotificationActions || oldest {
+		t.Errorf("tracked %d notifications (oldest kept: %v), want %d", count, oldest, maxNotificationActions)
+	}
+}
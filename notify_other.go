@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package main
+
+import "errors"
+
+// 다른 OS에서는 메뉴의 Last: 표시로만 결과를 보여준다
+func newNotifyBackend() (notifyBackend, error) {
+	return nil, errors.New("notifications are not supported on this OS")
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBackend는 보낸 알림을 기록한다.
+type fakeBackend struct {
+	mu    sync.Mutex
+	shown []Notification
+}
+
+func (b *fakeBackend) Show(n Notification) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.shown = append(b.shown, n)
+	return nil
+}
+
+func (b *fakeBackend) titles() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var titles []string
+	for _, n := range b.shown {
+		titles = append(titles, n.Title)
+	}
+	return titles
+}
+
+func newTestNotifier(cfg Config, interval time.Duration) (*Notifier, *fakeBackend) {
+	backend := &fakeBackend{}
+	return &Notifier{cfg: &cfg, cfgLock: &sync.Mutex{}, backend: backend, interval: interval}, backend
+}
+
+func findAction(t *testing.T, n Notification, key string) NotificationAction {
+	t.Helper()
+	for _, a := range n.Actions {
+		if a.Key == key {
+			return a
+		}
+	}
+	t.Fatalf("notification %q has no %q action", n.Title, key)
+	return NotificationAction{}
+}
+
+func TestNotifierThrottle(t *testing.T) {
+	n, backend := newTestNotifier(Config{}, 50*time.Millisecond)
+	ok := func(name string) RenameResult {
+		return RenameResult{OriginalPath: "/shots/shot.png", NewPath: "/shots/" + name, Success: true}
+	}
+
+	n.Notify(ok("a.png"))
+	n.Notify(ok("b.png"))
+	n.Notify(RenameResult{OriginalPath: "/shots/c.png", Error: errors.New("boom")})
+	n.Notify(RenameResult{Skipped: true})
+	if got := backend.titles(); len(got) != 1 || got[0] != "스크린샷 이름 변경" {
+		t.Fatalf("first notification should go out immediately: %v", got)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	backend.mu.Lock()
+	shown := backend.shown
+	backend.mu.Unlock()
+	if len(shown) != 2 {
+		t.Fatalf("burst should be summarized into one notification: %+v", shown)
+	}
+	if summary := shown[1]; summary.Title != "스크린샷 2개 처리 (실패 1개)" || summary.Body != "b.png" || len(summary.Actions) != 0 {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	// 한 개만 모였으면 요약 대신 원래 알림을 보낸다
+	n.Notify(ok("d.png"))
+	time.Sleep(150 * time.Millisecond)
+	if got := backend.titles(); len(got) != 3 || got[2] != "스크린샷 이름 변경" {
+		t.Errorf("single queued result should get a normal notification: %v", got)
+	}
+}
+
+func TestNotifierDisabled(t *testing.T) {
+	off := false
+	n, backend := newTestNotifier(Config{Notifications: &off}, time.Millisecond)
+	n.Notify(RenameResult{NewPath: "/shots/a.png", Success: true})
+	if len(backend.titles()) != 0 {
+		t.Error("notifications: false should suppress notifications")
+	}
+	var nilNotifier *Notifier
+	nilNotifier.Notify(RenameResult{Success: true})
+}
+
+func TestNotificationText(t *testing.T) {
+	n, _ := newTestNotifier(Config{}, time.Second)
+	tests := []struct {
+		name    string
+		result  RenameResult
+		title   string
+		actions string
+	}{
+		{"renamed", RenameResult{OriginalPath: "/s/shot.png", NewPath: "/s/a.png", Success: true}, "스크린샷 이름 변경", "undo,open,rename"},
+		{"failed", RenameResult{OriginalPath: "/s/shot.png", Error: errors.New("boom")}, "리네이밍 실패", "open"},
+		{"deleted", RenameResult{OriginalPath: "/s/shot.png", Success: true, Deleted: true}, "중복 스크린샷 삭제", ""},
+		{"pending", RenameResult{OriginalPath: "/s/shot.png", PendingID: 3, SuggestedName: "slack-chat"}, "승인 대기 #3", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := n.notification(tt.result)
+			var keys []string
+			for _, a := range got.Actions {
+				keys = append(keys, a.Key)
+			}
+			if got.Title != tt.title || strings.Join(keys, ",") != tt.actions {
+				t.Errorf("notification = %q %v, want %q %q", got.Title, keys, tt.title, tt.actions)
+			}
+		})
+	}
+}
+
+func TestNotificationActions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	original := filepath.Join(dir, "Screenshot 2025-01-15 at 12.30.45.png")
+	current := filepath.Join(dir, "2025-01-15_slack-chat.png")
+	if err := os.WriteFile(current, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result := RenameResult{OriginalPath: original, NewPath: current, Success: true, SuggestedName: "slack-chat"}
+
+	n, _ := newTestNotifier(Config{MaxFileNameLen: 80}, time.Second)
+	var changes []RenameResult
+	var ignored []string
+	n.onChange = func(r RenameResult) { changes = append(changes, r) }
+	n.ignore = func(path string) { ignored = append(ignored, path) }
+
+	saved := askName
+	t.Cleanup(func() { askName = saved })
+	askName = func(title, current string) (string, bool) {
+		if title != "2025-01-15_slack-chat.png" || current != "slack-chat" {
+			t.Errorf("askName(%q, %q)", title, current)
+		}
+		return "github-pr", true
+	}
+
+	notification := n.notification(result)
+	findAction(t, notification, "rename").Run()
+	renamed := filepath.Join(dir, "2025-01-15_github-pr.png")
+	if len(changes) != 1 || changes[0].NewPath != renamed {
+		t.Fatalf("after rename: %+v", changes)
+	}
+
+	// 되돌리기는 바뀐 이름에서 원래 이름으로 돌아간다
+	findAction(t, notification, "undo").Run()
+	if len(changes) != 2 || !changes[1].Undone {
+		t.Fatalf("after undo: %+v", changes)
+	}
+	if _, err := os.Stat(original); err != nil {
+		t.Errorf("original should be back: %v", err)
+	}
+	// 이름 바꾸기와 되돌리기 모두 옮기기 전에 최종 경로를 감시자에게 알린다
+	if len(ignored) != 2 || ignored[0] != renamed || ignored[1] != original {
+		t.Errorf("watcher should ignore the renamed and restored paths: %v", ignored)
+	}
+
+	// 이미 되돌렸으면 아무것도 바뀌지 않는다
+	findAction(t, notification, "undo").Run()
+	if len(changes) != 2 {
+		t.Errorf("second undo should fail quietly: %+v", changes)
+	}
+}
@@ -1,12 +1,61 @@
 import Foundation
 import Vision
 
-guard CommandLine.arguments.count > 1 else {
-    fputs("Usage: ocr-helper <image-path>\n", stderr)
+// 출력 형식:
+// {"lines":[{"text":"...","confidence":0.98,"box":{"x":0.1,"y":0.05,"width":0.4,"height":0.03}}]}
+// box는 이미지 크기 대비 0~1로 정규화한 좌상단 원점 좌표
+
+struct Box: Encodable {
+    let x: Double
+    let y: Double
+    let width: Double
+    let height: Double
+}
+
+struct Line: Encodable {
+    let text: String
+    let confidence: Double
+    let box: Box
+}
+
+struct Output: Encodable {
+    let lines: [Line]
+}
+
+let usage = "Usage: ocr-helper [--languages en-US,ko-KR] [--level accurate|fast] [--no-language-correction] <image-path>\n"
+
+var languages = ["en-US", "ko-KR"]
+var recognitionLevel = VNRequestTextRecognitionLevel.accurate
+var usesLanguageCorrection = true
+var imagePathArg: String?
+
+var args = CommandLine.arguments.dropFirst()
+while let arg = args.popFirst() {
+    switch arg {
+    case "--languages":
+        guard let value = args.popFirst() else {
+            fputs(usage, stderr)
+            exit(1)
+        }
+        languages = value.split(separator: ",").map { String($0) }
+    case "--level":
+        guard let value = args.popFirst(), value == "accurate" || value == "fast" else {
+            fputs(usage, stderr)
+            exit(1)
+        }
+        recognitionLevel = value == "fast" ? .fast : .accurate
+    case "--no-language-correction":
+        usesLanguageCorrection = false
+    default:
+        imagePathArg = arg
+    }
+}
+
+guard let imagePath = imagePathArg else {
+    fputs(usage, stderr)
     exit(1)
 }
 
-let imagePath = CommandLine.arguments[1]
 let imageURL = URL(fileURLWithPath: imagePath)
 
 guard FileManager.default.fileExists(atPath: imagePath) else {
@@ -21,7 +70,7 @@ guard let imageSource = CGImageSourceCreateWithURL(imageURL as CFURL, nil),
 }
 
 let semaphore = DispatchSemaphore(value: 0)
-var recognizedText = ""
+var recognizedLines: [Line] = []
 
 let request = VNRecognizeTextRequest { request, error in
     defer { semaphore.signal() }
@@ -30,13 +79,23 @@ let request = VNRecognizeTextRequest { request, error in
         return
     }
     guard let observations = request.results as? [VNRecognizedTextObservation] else { return }
-    let lines = observations.compactMap { $0.topCandidates(1).first?.string }
-    recognizedText = lines.joined(separator: "\n")
+    recognizedLines = observations.compactMap { observation in
+        guard let candidate = observation.topCandidates(1).first else { return nil }
+        // Vision은 좌하단 원점이므로 좌상단 원점으로 변환
+        let rect = observation.boundingBox
+        let box = Box(
+            x: Double(rect.origin.x),
+            y: Double(1 - rect.origin.y - rect.size.height),
+            width: Double(rect.size.width),
+            height: Double(rect.size.height)
+        )
+        return Line(text: candidate.string, confidence: Double(candidate.confidence), box: box)
+    }
 }
 
-request.recognitionLevel = .accurate
-request.recognitionLanguages = ["en-US", "ko-KR"]
-request.usesLanguageCorrection = true
+request.recognitionLevel = recognitionLevel
+request.recognitionLanguages = languages
+request.usesLanguageCorrection = usesLanguageCorrection
 
 let handler = VNImageRequestHandler(cgImage: cgImage, options: [:])
 do {
@@ -47,4 +106,11 @@ do {
 }
 
 semaphore.wait()
-print(recognizedText)
+
+let encoder = JSONEncoder()
+guard let data = try? encoder.encode(Output(lines: recognizedLines)),
+      let json = String(data: data, encoding: .utf8) else {
+    fputs("Error: cannot encode result\n", stderr)
+    exit(1)
+}
+print(json)
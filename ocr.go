@@ -1,34 +1,411 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
 	"os/exec"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type OCRResult struct {
-	Text    string
-	HasText bool
+	Text    string `json:"text"`
+	HasText bool   `json:"has_text"`
+	// 줄 단위 인식 결과 (읽는 순서). 구조화된 출력을 지원하지 않는 백엔드면 비어있을 수 있다.
+	Lines []OCRLine `json:"lines,omitempty"`
 }
 
-func RunOCR(cfg Config, imagePath string) OCRResult {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// OCRLine은 인식된 텍스트 한 줄과 신뢰도, 위치다.
+type OCRLine struct {
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"`
+	Box        Box     `json:"box"`
+}
+
+// Box는 이미지 크기 대비 0~1로 정규화한 영역이다 (좌상단 원점).
+type Box struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// 이보다 신뢰도가 낮은 줄은 노이즈로 보고 버린다
+const minOCRConfidence = 0.3
+
+// OCREngine은 텍스트 인식에 사용할 백엔드 종류다.
+type OCREngine string
+
+const (
+	OCREngineVision    OCREngine = "vision"
+	OCREngineTesseract OCREngine = "tesseract"
+)
+
+// OCR 인식 수준: accurate는 느리지만 정확하고, fast는 빠르지만 부정확하다
+const (
+	OCRLevelAccurate = "accurate"
+	OCRLevelFast     = "fast"
+)
+
+// 설정이 없을 때의 OCR 제한 시간 (초)
+const defaultOCRTimeout = 10
+
+// OCRPolicy는 네이밍 전에 OCR을 수행할지 결정하는 정책이다.
+type OCRPolicy string
+
+const (
+	// 항상 OCR 수행 (기본값)
+	OCRPolicyAlways OCRPolicy = "always"
+	// OCR 생략
+	OCRPolicyNever OCRPolicy = "never"
+	// 이미지를 직접 분석하지 못하는 프로바이더에서만 수행
+	OCRPolicyTextOnly OCRPolicy = "text-only"
+	// 텍스트가 많아 보이는 이미지에서만 수행
+	OCRPolicyTextHeavy OCRPolicy = "text-heavy"
+)
+
+// 밝기 경계 밀도가 이 값 이상이면 텍스트가 많은 이미지로 본다
+const textHeavyThreshold = 0.08
+
+// OCRBackend는 이미지에서 텍스트를 줄 단위로 추출한다.
+type OCRBackend interface {
+	Recognize(ctx context.Context, imagePath string) ([]OCRLine, error)
+}
+
+// visionBackend는 Apple Vision 기반 ocr-helper를 호출한다 (macOS 전용).
+type visionBackend struct {
+	helperPath         string
+	languages          []string
+	level              string
+	languageCorrection bool
+}
+
+func (b visionBackend) args(imagePath string) []string {
+	var args []string
+	if len(b.languages) > 0 {
+		args = append(args, "--languages", strings.Join(b.languages, ","))
+	}
+	if b.level != "" {
+		args = append(args, "--level", b.level)
+	}
+	if !b.languageCorrection {
+		args = append(args, "--no-language-correction")
+	}
+	return append(args, imagePath)
+}
+
+func (b visionBackend) Recognize(ctx context.Context, imagePath string) ([]OCRLine, error) {
+	out, err := exec.CommandContext(ctx, b.helperPath, b.args(imagePath)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ocr-helper error: %w", err)
+	}
+	return parseHelperOutput(out), nil
+}
+
+// parseHelperOutput은 ocr-helper의 JSON 출력을 파싱한다.
+// 이전 버전 helper처럼 일반 텍스트를 출력하면 줄 단위로 나눠 위치 정보 없이 사용한다.
+func parseHelperOutput(out []byte) []OCRLine {
+	var parsed struct {
+		Lines []OCRLine `json:"lines"`
+	}
+	if err := json.Unmarshal(out, &parsed); err == nil {
+		return parsed.Lines
+	}
+
+	var lines []OCRLine
+	for _, text := range strings.Split(string(out), "\n") {
+		if text = strings.TrimSpace(text); text != "" {
+			lines = append(lines, OCRLine{Text: text, Confidence: 1})
+		}
+	}
+	return lines
+}
+
+// tesseractBackend는 tesseract CLI를 호출한다.
+// 인식 수준과 언어 교정 설정은 Vision 전용이라 사용하지 않는다.
+type tesseractBackend struct {
+	path      string
+	languages []string
+}
+
+func (b tesseractBackend) Recognize(ctx context.Context, imagePath string) ([]OCRLine, error) {
+	out, err := exec.CommandContext(ctx, b.path,
+		imagePath, "stdout",
+		"-l", tesseractLanguages(b.languages),
+		"tsv",
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("tesseract error: %w", err)
+	}
+	return parseTesseractTSV(string(out)), nil
+}
+
+// parseTesseractTSV는 tesseract의 단어 단위 TSV 출력을 줄 단위로 묶는다.
+// 컬럼: level page_num block_num par_num line_num word_num left top width height conf text
+func parseTesseractTSV(tsv string) []OCRLine {
+	type lineAcc struct {
+		words                    []string
+		confSum                  float64
+		left, top, right, bottom int
+	}
+
+	var pageW, pageH int
+	var order []string
+	acc := make(map[string]*lineAcc)
+
+	for _, row := range strings.Split(tsv, "\n") {
+		cols := strings.Split(strings.TrimRight(row, "\r"), "\t")
+		if len(cols) < 12 || cols[0] == "level" {
+			continue
+		}
+		nums := make([]int, 10)
+		for i := range nums {
+			nums[i], _ = strconv.Atoi(cols[i])
+		}
+		left, top, width, height := nums[6], nums[7], nums[8], nums[9]
+		conf, _ := strconv.ParseFloat(cols[10], 64)
+		text := strings.TrimSpace(cols[11])
+
+		switch nums[0] {
+		case 1: // 페이지: 이미지 전체 크기
+			pageW, pageH = width, height
+		case 5: // 단어
+			if text == "" || conf < 0 {
+				continue
+			}
+			key := strings.Join(cols[1:5], ".")
+			l, ok := acc[key]
+			if !ok {
+				l = &lineAcc{left: left, top: top, right: left + width, bottom: top + height}
+				acc[key] = l
+				order = append(order, key)
+			}
+			l.words = append(l.words, text)
+			l.confSum += conf
+			l.left, l.top = min(l.left, left), min(l.top, top)
+			l.right, l.bottom = max(l.right, left+width), max(l.bottom, top+height)
+		}
+	}
+
+	lines := make([]OCRLine, 0, len(order))
+	for _, key := range order {
+		l := acc[key]
+		line := OCRLine{
+			Text:       strings.Join(l.words, " "),
+			Confidence: l.confSum / float64(len(l.words)) / 100,
+		}
+		if pageW > 0 && pageH > 0 {
+			line.Box = Box{
+				X:      float64(l.left) / float64(pageW),
+				Y:      float64(l.top) / float64(pageH),
+				Width:  float64(l.right-l.left) / float64(pageW),
+				Height: float64(l.bottom-l.top) / float64(pageH),
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// BCP 47 언어 태그 → tesseract traineddata 이름
+var tesseractLangCodes = map[string]string{
+	"en":      "eng",
+	"ko":      "kor",
+	"ja":      "jpn",
+	"zh-Hans": "chi_sim",
+	"zh-Hant": "chi_tra",
+	"de":      "deu",
+	"fr":      "fra",
+	"es":      "spa",
+}
+
+// tesseractLanguages는 "en-US", "ko-KR" 같은 언어 목록을 "eng+kor" 형식으로 바꾼다.
+func tesseractLanguages(languages []string) string {
+	var codes []string
+	seen := make(map[string]bool)
+	for _, lang := range languages {
+		code, ok := tesseractLangCodes[lang]
+		if !ok {
+			code, ok = tesseractLangCodes[strings.SplitN(lang, "-", 2)[0]]
+		}
+		if !ok || seen[code] {
+			continue
+		}
+		seen[code] = true
+		codes = append(codes, code)
+	}
+	if len(codes) == 0 {
+		return "eng"
+	}
+	return strings.Join(codes, "+")
+}
+
+// defaultOCREngine은 macOS에서는 Vision, 그 외에는 tesseract를 사용한다.
+func defaultOCREngine() OCREngine {
+	if runtime.GOOS == "darwin" {
+		return OCREngineVision
+	}
+	return OCREngineTesseract
+}
+
+func newOCRBackend(cfg Config) OCRBackend {
+	languages := cfg.OCRLanguages
+	if len(languages) == 0 {
+		languages = systemOCRLanguages()
+	}
+
+	switch cfg.OCREngine {
+	case OCREngineTesseract:
+		return tesseractBackend{path: cfg.TesseractPath, languages: languages}
+	default:
+		return visionBackend{
+			helperPath:         cfg.OCRHelperPath,
+			languages:          languages,
+			level:              cfg.OCRLevel,
+			languageCorrection: cfg.LanguageCorrection(),
+		}
+	}
+}
+
+func RunOCR(cfg Config, imagePath string) (OCRResult, error) {
+	timeout := cfg.OCRTimeout
+	if timeout <= 0 {
+		timeout = defaultOCRTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, cfg.OCRHelperPath, imagePath)
-	out, err := cmd.Output()
+	lines, err := newOCRBackend(cfg).Recognize(ctx, imagePath)
 	if err != nil {
-		fmt.Printf("[OCR] error: %v\n", err)
-		return OCRResult{}
+		ocrLog.Error("OCR 실패", "path", imagePath, "engine", cfg.OCREngine, "err", err)
+		return OCRResult{}, err
 	}
+	return newOCRResult(lines), nil
+}
+
+// providerSeesImage는 프로바이더가 이미지를 직접 분석할 수 있는지 여부다.
+func providerSeesImage(p Provider) bool {
+	switch p {
+	case ProviderClaude, ProviderCodex:
+		return true
+	default:
+		return false
+	}
+}
 
-	text := strings.TrimSpace(string(out))
+// decideOCR은 정책에 따라 OCR 수행 여부와 그 이유를 반환한다.
+func decideOCR(cfg Config, imagePath string) (bool, string) {
+	switch cfg.OCRPolicy {
+	case OCRPolicyNever:
+		return false, "policy never"
+	case OCRPolicyTextOnly:
+		if providerSeesImage(cfg.Provider) {
+			return false, fmt.Sprintf("%s analyzes image directly", cfg.Provider)
+		}
+		return true, fmt.Sprintf("%s is text-only", cfg.Provider)
+	case OCRPolicyTextHeavy:
+		density, err := textDensity(imagePath)
+		if err != nil {
+			// 판단할 수 없으면 OCR을 수행하는 쪽이 안전
+			return true, fmt.Sprintf("text density unknown: %v", err)
+		}
+		if density >= textHeavyThreshold {
+			return true, fmt.Sprintf("text-heavy image (density %.3f)", density)
+		}
+		return false, fmt.Sprintf("not text-heavy (density %.3f)", density)
+	default:
+		return true, "policy always"
+	}
+}
+
+// textDensity는 이미지를 격자로 샘플링해 인접 픽셀 간 밝기 차이가 큰 경계의 비율을 구한다.
+// 글자는 짧은 간격으로 명암이 자주 바뀌므로 텍스트가 많을수록 값이 크다.
+func textDensity(imagePath string) (float64, error) {
+	f, err := os.Open(imagePath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return 0, err
+	}
+
+	// 긴 변 기준 최대 512 샘플 (Retina 스크린샷도 빠르게)
+	bounds := img.Bounds()
+	step := max(1, max(bounds.Dx(), bounds.Dy())/512)
+
+	var edges, total int
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		prev := -1
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			lum := int(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+			if prev >= 0 {
+				total++
+				if d := lum - prev; d > 64 || d < -64 {
+					edges++
+				}
+			}
+			prev = lum
+		}
+	}
+	if total == 0 {
+		return 0, nil
+	}
+	return float64(edges) / float64(total), nil
+}
+
+// newOCRResult는 신뢰도가 낮은 줄을 버리고 남은 줄로 결과를 만든다.
+func newOCRResult(lines []OCRLine) OCRResult {
+	var kept []OCRLine
+	var texts []string
+	for _, line := range lines {
+		line.Text = strings.TrimSpace(line.Text)
+		if line.Text == "" || line.Confidence < minOCRConfidence {
+			continue
+		}
+		kept = append(kept, line)
+		texts = append(texts, line.Text)
+	}
+
+	text := strings.Join(texts, "\n")
 	// 3글자 미만이면 의미있는 텍스트가 아닌 것으로 판단
 	if len([]rune(text)) < 3 {
-		return OCRResult{Text: text, HasText: false}
+		return OCRResult{Text: text, HasText: false, Lines: kept}
 	}
 
-	return OCRResult{Text: text, HasText: true}
+	return OCRResult{Text: text, HasText: true, Lines: kept}
+}
+
+// Prominent는 화면에서 눈에 띄는 줄(글자가 크고 위쪽에 있는 창 제목, 헤딩 등)부터 정렬한 텍스트를 반환한다.
+// 위치 정보가 없으면 읽는 순서를 그대로 사용한다.
+func (r OCRResult) Prominent() string {
+	if len(r.Lines) == 0 {
+		return r.Text
+	}
+	lines := slices.Clone(r.Lines)
+	slices.SortStableFunc(lines, func(a, b OCRLine) int {
+		return cmp.Compare(prominence(b), prominence(a))
+	})
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.Text
+	}
+	return strings.Join(texts, "\n")
+}
+
+// prominence는 글자 높이를 기본으로, 화면 위쪽일수록 가중치를 준 점수다.
+func prominence(l OCRLine) float64 {
+	return l.Box.Height * (1.5 - l.Box.Y) * l.Confidence
 }
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTesseractLanguages(t *testing.T) {
+	tests := []struct {
+		name      string
+		languages []string
+		want      string
+	}{
+		{"english korean", []string{"en-US", "ko-KR"}, "eng+kor"},
+		{"base language", []string{"ja"}, "jpn"},
+		{"script subtag", []string{"zh-Hans", "zh-Hant"}, "chi_sim+chi_tra"},
+		{"duplicates removed", []string{"en-US", "en-GB"}, "eng"},
+		{"unknown skipped", []string{"xx-YY", "ko-KR"}, "kor"},
+		{"empty defaults to english", nil, "eng"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tesseractLanguages(tt.languages); got != tt.want {
+				t.Errorf("tesseractLanguages(%v) = %q, want %q", tt.languages, got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeCLI는 인자를 파일에 기록하고 지정한 텍스트를 출력하는 스크립트를 만든다.
+func fakeCLI(t *testing.T, output string) (path, argsFile string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script fake requires a POSIX shell")
+	}
+	dir := t.TempDir()
+	path = filepath.Join(dir, "fake-cli")
+	argsFile = filepath.Join(dir, "args")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + argsFile + "\nprintf '%s' '" + output + "'\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path, argsFile
+}
+
+func readArgs(t *testing.T, argsFile string) []string {
+	t.Helper()
+	data, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+}
+
+func TestRunOCR(t *testing.T) {
+	t.Run("tesseract backend", func(t *testing.T) {
+		bin, argsFile := fakeCLI(t, sampleTSV)
+		cfg := Config{OCREngine: OCREngineTesseract, TesseractPath: bin, OCRLanguages: []string{"en-US", "ko-KR"}}
+
+		got, _ := RunOCR(cfg, "/shots/image.png")
+		if !got.HasText || got.Text != "Hello World\nsecond line" {
+			t.Errorf("RunOCR = %+v, want joined lines", got)
+		}
+		want := []string{"/shots/image.png", "stdout", "-l", "eng+kor", "tsv"}
+		if args := readArgs(t, argsFile); strings.Join(args, " ") != strings.Join(want, " ") {
+			t.Errorf("tesseract args = %q, want %q", args, want)
+		}
+	})
+
+	t.Run("vision backend", func(t *testing.T) {
+		bin, argsFile := fakeCLI(t, "슬랙 대화")
+		cfg := Config{
+			OCREngine:     OCREngineVision,
+			OCRHelperPath: bin,
+			OCRLanguages:  []string{"ja-JP", "en-US"},
+			OCRLevel:      OCRLevelFast,
+		}
+
+		got, _ := RunOCR(cfg, "/shots/image.png")
+		if !got.HasText || got.Text != "슬랙 대화" {
+			t.Errorf("RunOCR = %+v, want helper output", got)
+		}
+		want := []string{"--languages", "ja-JP,en-US", "--level", "fast", "/shots/image.png"}
+		if args := readArgs(t, argsFile); strings.Join(args, " ") != strings.Join(want, " ") {
+			t.Errorf("ocr-helper args = %q, want %q", args, want)
+		}
+	})
+
+	t.Run("vision backend json", func(t *testing.T) {
+		bin, _ := fakeCLI(t, `{"lines":[{"text":"Slack","confidence":0.9,"box":{"x":0,"y":0,"width":0.2,"height":0.05}}]}`)
+		got, _ := RunOCR(Config{OCREngine: OCREngineVision, OCRHelperPath: bin}, "/img.png")
+		if !got.HasText || got.Text != "Slack" || len(got.Lines) != 1 || got.Lines[0].Box.Height != 0.05 {
+			t.Errorf("RunOCR = %+v, want parsed JSON line", got)
+		}
+	})
+
+	t.Run("short text has no text", func(t *testing.T) {
+		bin, _ := fakeCLI(t, "ab")
+		got, _ := RunOCR(Config{OCREngine: OCREngineVision, OCRHelperPath: bin}, "/img.png")
+		if got.HasText {
+			t.Error("text shorter than 3 runes should not count as text")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		bin, _ := fakeCLI(t, "late")
+		os.WriteFile(bin, []byte("#!/bin/sh\nexec sleep 5\n"), 0755)
+		start := time.Now()
+		got, err := RunOCR(Config{OCREngine: OCREngineVision, OCRHelperPath: bin, OCRTimeout: 1}, "/img.png")
+		if got.HasText || err == nil {
+			t.Errorf("timed out OCR = %+v, %v, want no text and an error", got, err)
+		}
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("RunOCR took %v, want about 1s timeout", elapsed)
+		}
+	})
+
+	t.Run("backend error", func(t *testing.T) {
+		cfg := Config{OCREngine: OCREngineTesseract, TesseractPath: filepath.Join(t.TempDir(), "missing")}
+		if got, err := RunOCR(cfg, "/img.png"); got.HasText || got.Text != "" || err == nil {
+			t.Errorf("RunOCR with missing binary = %+v, %v, want empty result and an error", got, err)
+		}
+	})
+}
+
+const sampleTSV = "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+	"1\t1\t0\t0\t0\t0\t0\t0\t1000\t500\t-1\t\n" +
+	"4\t1\t1\t1\t1\t0\t100\t50\t300\t40\t-1\t\n" +
+	"5\t1\t1\t1\t1\t1\t100\t50\t120\t40\t96\tHello\n" +
+	"5\t1\t1\t1\t1\t2\t250\t55\t150\t30\t90\tWorld\n" +
+	"5\t1\t1\t1\t2\t1\t100\t200\t80\t20\t80\tsecond\n" +
+	"5\t1\t1\t1\t2\t2\t190\t200\t50\t20\t70\tline\n" +
+	"5\t1\t2\t1\t1\t1\t10\t450\t20\t10\t12\t~\n" +
+	"5\t1\t2\t1\t1\t2\t40\t450\t20\t10\t-1\t \n"
+
+func TestParseTesseractTSV(t *testing.T) {
+	lines := parseTesseractTSV(sampleTSV)
+	if len(lines) != 3 {
+		t.Fatalf("len(lines) = %d, want 3: %+v", len(lines), lines)
+	}
+
+	first := lines[0]
+	if first.Text != "Hello World" {
+		t.Errorf("first line text = %q, want %q", first.Text, "Hello World")
+	}
+	if first.Confidence != 0.93 {
+		t.Errorf("first line confidence = %v, want 0.93 (word average)", first.Confidence)
+	}
+	wantBox := Box{X: 0.1, Y: 0.1, Width: 0.3, Height: 0.08}
+	if first.Box != wantBox {
+		t.Errorf("first line box = %+v, want %+v (union of words)", first.Box, wantBox)
+	}
+	if lines[2].Confidence != 0.12 {
+		t.Errorf("noise line confidence = %v, want 0.12", lines[2].Confidence)
+	}
+}
+
+func TestParseHelperOutput(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		lines := parseHelperOutput([]byte(`{"lines":[{"text":"a","confidence":0.5,"box":{"x":0.1,"y":0.2,"width":0.3,"height":0.4}}]}`))
+		want := OCRLine{Text: "a", Confidence: 0.5, Box: Box{0.1, 0.2, 0.3, 0.4}}
+		if len(lines) != 1 || lines[0] != want {
+			t.Errorf("lines = %+v, want %+v", lines, want)
+		}
+	})
+
+	t.Run("plain text fallback", func(t *testing.T) {
+		lines := parseHelperOutput([]byte("first\n\n second \n"))
+		if len(lines) != 2 || lines[0].Text != "first" || lines[1].Text != "second" || lines[0].Confidence != 1 {
+			t.Errorf("lines = %+v, want two full-confidence lines", lines)
+		}
+	})
+}
+
+func TestNewOCRResult(t *testing.T) {
+	result := newOCRResult([]OCRLine{
+		{Text: "Pull request #42", Confidence: 0.95},
+		{Text: "~~", Confidence: 0.1},
+		{Text: "  ", Confidence: 0.9},
+		{Text: "Merge", Confidence: 0.6},
+	})
+	if result.Text != "Pull request #42\nMerge" {
+		t.Errorf("Text = %q, want low-confidence and empty lines dropped", result.Text)
+	}
+	if len(result.Lines) != 2 || !result.HasText {
+		t.Errorf("result = %+v, want 2 kept lines with text", result)
+	}
+}
+
+func TestOCRResultProminent(t *testing.T) {
+	t.Run("large top text first", func(t *testing.T) {
+		result := OCRResult{Lines: []OCRLine{
+			{Text: "small footer", Confidence: 1, Box: Box{Y: 0.9, Height: 0.02}},
+			{Text: "Window Title", Confidence: 1, Box: Box{Y: 0.01, Height: 0.03}},
+			{Text: "Big Heading", Confidence: 1, Box: Box{Y: 0.3, Height: 0.08}},
+		}}
+		want := "Big Heading\nWindow Title\nsmall footer"
+		if got := result.Prominent(); got != want {
+			t.Errorf("Prominent() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("without boxes keeps reading order", func(t *testing.T) {
+		result := OCRResult{Lines: []OCRLine{
+			{Text: "first", Confidence: 1},
+			{Text: "second", Confidence: 1},
+		}}
+		if got := result.Prominent(); got != "first\nsecond" {
+			t.Errorf("Prominent() = %q, want reading order", got)
+		}
+	})
+
+	t.Run("without lines uses text", func(t *testing.T) {
+		result := OCRResult{Text: "plain", HasText: true}
+		if got := result.Prominent(); got != "plain" {
+			t.Errorf("Prominent() = %q, want plain", got)
+		}
+	})
+}
+
+func TestVisionBackendArgs(t *testing.T) {
+	off := false
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"defaults", Config{OCRLanguages: []string{"en-US", "ko-KR"}, OCRLevel: OCRLevelAccurate},
+			"--languages en-US,ko-KR --level accurate /img.png"},
+		{"no language correction", Config{OCRLanguages: []string{"zh-Hans"}, OCRLanguageCorrection: &off},
+			"--languages zh-Hans --no-language-correction /img.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newOCRBackend(tt.cfg).(visionBackend)
+			if got := strings.Join(b.args("/img.png"), " "); got != tt.want {
+				t.Errorf("args = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// writeTestPNG는 테스트용 PNG를 만든다. striped가 true면 글자처럼 명암이 자주 바뀌는 줄무늬를 그린다.
+func writeTestPNG(t *testing.T, dir, name string, striped bool) string {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, 200, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 200; x++ {
+			c := color.Gray{Y: 240}
+			if striped && (x/2)%2 == 0 && (y/4)%2 == 0 {
+				c = color.Gray{Y: 20}
+			}
+			img.SetGray(x, y, c)
+		}
+	}
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTextDensity(t *testing.T) {
+	dir := t.TempDir()
+
+	blank, err := textDensity(writeTestPNG(t, dir, "blank.png", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blank != 0 {
+		t.Errorf("blank image density = %v, want 0", blank)
+	}
+
+	text, err := textDensity(writeTestPNG(t, dir, "text.png", true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text < textHeavyThreshold {
+		t.Errorf("striped image density = %v, want >= %v", text, textHeavyThreshold)
+	}
+
+	if _, err := textDensity(filepath.Join(dir, "missing.png")); err == nil {
+		t.Error("missing file should return error")
+	}
+}
+
+func TestDecideOCR(t *testing.T) {
+	dir := t.TempDir()
+	blank := writeTestPNG(t, dir, "blank.png", false)
+	text := writeTestPNG(t, dir, "text.png", true)
+
+	tests := []struct {
+		name     string
+		policy   OCRPolicy
+		provider Provider
+		image    string
+		want     bool
+	}{
+		{"default always", "", ProviderClaude, blank, true},
+		{"always", OCRPolicyAlways, ProviderClaude, blank, true},
+		{"never", OCRPolicyNever, ProviderClaude, text, false},
+		{"text-only with image provider", OCRPolicyTextOnly, ProviderCodex, text, false},
+		{"text-only with unknown provider", OCRPolicyTextOnly, Provider("local-llm"), blank, true},
+		{"text-heavy image", OCRPolicyTextHeavy, ProviderClaude, text, true},
+		{"plain image", OCRPolicyTextHeavy, ProviderClaude, blank, false},
+		{"undecodable image runs OCR", OCRPolicyTextHeavy, ProviderClaude, filepath.Join(dir, "missing.png"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := decideOCR(Config{OCRPolicy: tt.policy, Provider: tt.provider}, tt.image)
+			if got != tt.want {
+				t.Errorf("decideOCR = %v (%s), want %v", got, reason, tt.want)
+			}
+			if reason == "" {
+				t.Error("decision should include a reason")
+			}
+		})
+	}
+}
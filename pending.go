@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// PendingItem은 confirm_rename 모드에서 승인을 기다리는 리네이밍이다.
+type PendingItem struct {
+	ID   int    `json:"id"`
+	Path string `json:"path"`
+	// 제안된 이름과 다른 후보 (템플릿 적용 전)
+	Name         string    `json:"name"`
+	Alternatives []string  `json:"alternatives,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+var errPendingNotFound = errors.New("no such pending item")
+
+// 대기열 파일 읽기/쓰기를 직렬화한다
+var pendingMu sync.Mutex
+
+func pendingPath() string {
+	return filepath.Join(configDir(), "pending.json")
+}
+
+func readPending() ([]PendingItem, error) {
+	data, err := os.ReadFile(pendingPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var items []PendingItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", pendingPath(), err)
+	}
+	return items, nil
+}
+
+func writePending(items []PendingItem) error {
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+	// 쓰다가 종료돼도 대기열이 깨지지 않도록 임시 파일에 쓰고 바꾼다.
+	// 제안 이름은 화면 내용에서 나오므로 본인만 읽을 수 있게 한다.
+	tmp := pendingPath() + ".tmp"
+	if err := writePrivateFile(tmp, data); err != nil {
+		return err
+	}
+	return os.Rename(tmp, pendingPath())
+}
+
+// ListPending은 승인 대기 중인 항목을 들어온 순서로 반환한다.
+func ListPending() ([]PendingItem, error) {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	return readPending()
+}
+
+// addPending은 항목을 대기열에 넣는다. 같은 파일이 이미 있으면 이름만 바꾼다.
+// 번호가 없는 새 항목에는 다음 번호를 붙이고, 되돌려 넣는 항목은 번호를 유지한다.
+func addPending(item PendingItem) (PendingItem, error) {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+
+	items, err := readPending()
+	if err != nil {
+		return item, err
+	}
+	if i := slices.IndexFunc(items, func(p PendingItem) bool { return p.Path == item.Path }); i >= 0 {
+		item.ID = items[i].ID
+		items[i] = item
+	} else {
+		if item.ID == 0 {
+			for _, p := range items {
+				item.ID = max(item.ID, p.ID)
+			}
+			item.ID++
+		}
+		items = append(items, item)
+	}
+	return item, writePending(items)
+}
+
+// takePending은 항목을 대기열에서 꺼낸다.
+func takePending(id int) (PendingItem, error) {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+
+	items, err := readPending()
+	if err != nil {
+		return PendingItem{}, err
+	}
+	i := slices.IndexFunc(items, func(p PendingItem) bool { return p.ID == id })
+	if i < 0 {
+		return PendingItem{}, fmt.Errorf("%w: %d", errPendingNotFound, id)
+	}
+	item := items[i]
+	return item, writePending(slices.Delete(items, i, i+1))
+}
+
+// ApprovePending은 항목을 승인하고 리네이밍한다. name이 비어 있으면 제안된 이름을 쓴다.
+// 설정은 승인 시점의 것에 항목이 속한 감시 디렉토리 설정을 적용해 쓴다 (템플릿, destination 등).
+// ignore는 SwitchName과 같다.
+func ApprovePending(cfg Config, id int, name string, ignore func(path string)) RenameResult {
+	item, err := takePending(id)
+	if err != nil {
+		return RenameResult{Error: err}
+	}
+	if d, ok := cfg.DirFor(item.Path); ok {
+		cfg = cfg.ForDir(d)
+	}
+	result := RenameResult{OriginalPath: item.Path, SuggestedName: item.Name, Alternatives: item.Alternatives}
+	if name != "" && name != item.Name {
+		// 직접 입력한 이름도 제안 이름과 같은 규칙으로 정리한다
+		name = finishNames(cfg, []string{name})[0]
+		result.Alternatives = slices.DeleteFunc(append([]string{item.Name}, item.Alternatives...), func(s string) bool { return s == name })
+		result.SuggestedName = name
+	}
+	if _, err := os.Stat(item.Path); err != nil {
+		result.Error = fmt.Errorf("pending file gone: %w", err)
+		pendingLog.Warn("파일 없음 - 대기열에서 제거", "id", item.ID, "path", item.Path)
+		return result
+	}
+	pendingLog.Info("승인", "id", item.ID, "path", item.Path, "name", result.SuggestedName)
+	result = moveTo(item.Path, targetPath(cfg, item.Path, result.SuggestedName), result, ignore)
+	if !result.Success {
+		// 리네이밍에 실패하면 다시 승인할 수 있게 대기열로 돌려놓는다
+		if _, err := addPending(item); err != nil {
+			pendingLog.Error("대기열 복원 실패", "id", item.ID, "err", err)
+		}
+	}
+	return result
+}
+
+// RejectPending은 항목을 대기열에서 빼고 파일은 그대로 둔다.
+func RejectPending(id int) error {
+	item, err := takePending(id)
+	if err != nil {
+		return err
+	}
+	pendingLog.Info("거절", "id", item.ID, "path", item.Path)
+	return nil
+}
+
+// queueRename은 리네이밍 대신 제안을 대기열에 넣는다.
+func queueRename(screenshotPath string, result RenameResult) RenameResult {
+	item, err := addPending(PendingItem{
+		Path:         screenshotPath,
+		Name:         result.SuggestedName,
+		Alternatives: result.Alternatives,
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		result.Error = fmt.Errorf("queue rename failed: %w", err)
+		result.log().Error("대기열 저장 실패", "err", err)
+		return result
+	}
+	result.PendingID = item.ID
+	result.log().Info("승인 대기", "id", item.ID, "name", item.Name)
+	return result
+}
+
+// askPendingName은 승인 전에 이름을 고칠 수 있게 입력 창을 띄운다.
+func askPendingName(item PendingItem) (string, bool) {
+	return askName(filepath.Base(item.Path), item.Name)
+}
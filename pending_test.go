@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPendingQueue(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	a, err := addPending(PendingItem{Path: "/shots/a.png", Name: "slack-chat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := addPending(PendingItem{Path: "/shots/b.png", Name: "github-pr"})
+	if a.ID != 1 || b.ID != 2 {
+		t.Errorf("IDs = %d, %d, want 1, 2", a.ID, b.ID)
+	}
+
+	// 같은 파일을 다시 넣으면 번호는 그대로, 이름만 바뀐다
+	again, _ := addPending(PendingItem{Path: "/shots/a.png", Name: "slack-dm"})
+	items, err := ListPending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != 1 || len(items) != 2 || items[0].Name != "slack-dm" {
+		t.Errorf("after re-queue: id %d, items %+v", again.ID, items)
+	}
+
+	if err := RejectPending(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := RejectPending(1); !errors.Is(err, errPendingNotFound) {
+		t.Errorf("second reject = %v, want errPendingNotFound", err)
+	}
+	if items, _ := ListPending(); len(items) != 1 || items[0].ID != 2 {
+		t.Errorf("after reject: %+v", items)
+	}
+	if info, err := os.Stat(pendingPath()); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("pending file = %v, %v, want 0600 file", info, err)
+	}
+}
+
+func TestApprovePending(t *testing.T) {
+	tests := []struct {
+		name     string
+		edit     string
+		want     string
+		wantAlts string
+	}{
+		{"suggested", "", "2025-01-15_slack-chat.png", "slack-dm"},
+		{"edited", "Slack 배포 논의", "2025-01-15_slack-배포-논의.png", "slack-chat,slack-dm"},
+		{"picked alternative", "slack-dm", "2025-01-15_slack-dm.png", "slack-chat"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			dir := t.TempDir()
+			shot := writeTestPNG(t, dir, "Screenshot 2025-01-15 at 12.30.45.png", false)
+			item, _ := addPending(PendingItem{Path: shot, Name: "slack-chat", Alternatives: []string{"slack-dm"}})
+
+			var ignored []string
+			ignore := func(path string) { ignored = append(ignored, path) }
+			result := ApprovePending(Config{MaxFileNameLen: 80, NameCase: NameCaseKebab}, item.ID, tt.edit, ignore)
+			if !result.Success {
+				t.Fatalf("ApprovePending failed: %v", result.Error)
+			}
+			if want := filepath.Join(dir, tt.want); result.NewPath != want {
+				t.Errorf("NewPath = %q, want %q", result.NewPath, want)
+			}
+			// 감시자가 승인한 이동을 새 스크린샷으로 처리하지 않도록 옮기기 전에 알린다
+			if len(ignored) != 1 || ignored[0] != result.NewPath {
+				t.Errorf("ignored = %q, want [%q]", ignored, result.NewPath)
+			}
+			if got := strings.Join(result.Alternatives, ","); got != tt.wantAlts {
+				t.Errorf("Alternatives = %q, want %q", got, tt.wantAlts)
+			}
+			if items, _ := ListPending(); len(items) != 0 {
+				t.Errorf("approved item should leave the queue: %+v", items)
+			}
+		})
+	}
+
+	t.Run("file gone", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		item, _ := addPending(PendingItem{Path: filepath.Join(t.TempDir(), "gone.png"), Name: "slack-chat"})
+		if result := ApprovePending(Config{}, item.ID, "", nil); result.Error == nil {
+			t.Error("approving a missing file should fail")
+		}
+		if items, _ := ListPending(); len(items) != 0 {
+			t.Error("missing file should be dropped from the queue")
+		}
+	})
+
+	t.Run("rename failure keeps item", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		shot := writeTestPNG(t, t.TempDir(), "Screenshot 2025-01-15 at 12.30.45.png", false)
+		blocker := filepath.Join(t.TempDir(), "file")
+		os.WriteFile(blocker, nil, 0644)
+		item, _ := addPending(PendingItem{Path: shot, Name: "slack-chat"})
+
+		// destination이 파일이라 디렉토리를 만들 수 없다
+		if result := ApprovePending(Config{Destination: filepath.Join(blocker, "sub")}, item.ID, "", nil); result.Success {
+			t.Fatal("rename into an invalid destination should fail")
+		}
+		if items, _ := ListPending(); len(items) != 1 || items[0].ID != item.ID {
+			t.Errorf("failed approval should stay queued: %+v", items)
+		}
+	})
+}
+
+func TestProcessScreenshotConfirmRename(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	shot := writeTestPNG(t, dir, "Screenshot 2025-01-15 at 12.30.45.png", true)
+	claude, _ := fakeCLI(t, "slack-chat")
+	cfg := Config{Provider: ProviderClaude, ClaudePath: claude, MaxFileNameLen: 80, OCRPolicy: OCRPolicyNever, ConfirmRename: true}
+
+	result := ProcessScreenshot(cfg, shot)
+	if result.Success || result.Error != nil || result.PendingID == 0 {
+		t.Fatalf("result = %+v, want queued without renaming", result)
+	}
+	if _, err := os.Stat(shot); err != nil {
+		t.Errorf("original should stay until approved: %v", err)
+	}
+	items, _ := ListPending()
+	if len(items) != 1 || items[0].Path != shot || items[0].Name != "slack-chat" {
+		t.Errorf("pending = %+v", items)
+	}
+}
+
+func TestRunPending(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	run := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := runCLI(append([]string{"pending"}, args...), nil, &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	if code, out, _ := run(); code != 0 || !strings.Contains(out, "승인 대기 없음") {
+		t.Errorf("empty list = %d %q", code, out)
+	}
+
+	dir := t.TempDir()
+	first := writeTestPNG(t, dir, "Screenshot 2025-01-15 at 12.30.45.png", false)
+	second := writeTestPNG(t, dir, "Screenshot 2025-01-16 at 09.00.00.png", false)
+	third := writeTestPNG(t, dir, "Screenshot 2025-01-17 at 10.00.00.png", false)
+	addPending(PendingItem{Path: first, Name: "slack-chat", Alternatives: []string{"slack-dm"}})
+	addPending(PendingItem{Path: second, Name: "github-pr"})
+	addPending(PendingItem{Path: third, Name: "figma-design"})
+
+	if code, out, _ := run("list"); code != 0 || !strings.Contains(out, "#1  "+first+" → slack-chat  (후보: slack-dm)") {
+		t.Errorf("list = %d %q", code, out)
+	}
+	if code, out, stderr := run("approve", "1", "slack-dm"); code != 0 || !strings.Contains(out, "2025-01-15_slack-dm.png") {
+		t.Errorf("approve = %d %q %q", code, out, stderr)
+	}
+	if code, out, _ := run("reject", "#2"); code != 0 || !strings.Contains(out, "거절 #2") {
+		t.Errorf("reject = %d %q", code, out)
+	}
+	if _, err := os.Stat(second); err != nil {
+		t.Error("rejected file should keep its name")
+	}
+	if code, _, _ := run("approve", "2"); code != 1 {
+		t.Errorf("approving a handled item exit = %d, want 1", code)
+	}
+	if code, out, _ := run("approve", "all"); code != 0 || !strings.Contains(out, "2025-01-17_figma-design.png") {
+		t.Errorf("approve all = %d %q", code, out)
+	}
+
+	for _, args := range [][]string{{"approve"}, {"reject", "1", "name"}, {"approve", "all", "name"}, {"bogus", "1"}, {"approve", "x"}} {
+		if code, _, _ := run(args...); code != 2 {
+			t.Errorf("pending %v exit = %d, want 2", args, code)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// PollWatcher는 주기적으로 디렉토리를 스캔해 새 스크린샷을 감지한다.
+// fsnotify 이벤트가 오지 않는 네트워크/FUSE 파일 시스템용이다.
+type PollWatcher struct {
+	// 감지 이후 처리(중복 방지, 자체 리네이밍 무시)는 fsnotify 감시자와 공유
+	core *Watcher
+
+	done  chan struct{}
+	files map[string]pollEntry
+}
+
+type pollEntry struct {
+	size    int64
+	modTime time.Time
+	// 이미 새 파일로 보고했는지 여부
+	reported bool
+}
+
+func NewPollWatcher(cfg *Config, cfgLock *sync.Mutex, onRenamed func(RenameResult)) *PollWatcher {
+	return &PollWatcher{
+		core: newWatcher(cfg, cfgLock, nil, onRenamed),
+		done: make(chan struct{}),
+	}
+}
+
+func (p *PollWatcher) Start() error {
+	p.core.cfgLock.Lock()
+	dirs := p.core.cfg.Dirs()
+	interval := time.Duration(p.core.cfg.PollInterval) * time.Second
+	p.core.cfgLock.Unlock()
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	// 사라진 파일은 바로, 새 파일은 다음 스캔에서 크기가 그대로일 때 보고하므로
+	// 이동의 Rename과 Create 사이에 폴링 간격 하나 이상이 걸린다
+	p.core.pairWindow = 2*interval + renamePairWindow
+
+	// 일부 디렉토리를 읽지 못해도 나머지는 계속 감시한다
+	var errs []error
+	for _, d := range dirs {
+		path := expandHome(d.Path)
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			errs = append(errs, fmt.Errorf("failed to watch %s: not a directory", path))
+			continue
+		}
+		watcherLog.Info("폴링 감시 시작", "dir", path, "interval", interval)
+	}
+	if len(errs) == len(dirs) {
+		return errors.Join(errs...)
+	}
+
+	// 시작 시점에 이미 있는 파일은 처리하지 않는다
+	p.files = p.scan(dirs)
+	for path, e := range p.files {
+		e.reported = true
+		p.files[path] = e
+	}
+
+	go p.loop(interval)
+	return errors.Join(errs...)
+}
+
+func (p *PollWatcher) Stop() {
+	close(p.done)
+}
+
+func (p *PollWatcher) Ignore(path string) {
+	p.core.Ignore(path)
+}
+
+func (p *PollWatcher) Track(result RenameResult) {
+	p.core.Track(result)
+}
+
+func (p *PollWatcher) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.core.cfgLock.Lock()
+			dirs := p.core.cfg.Dirs()
+			p.core.cfgLock.Unlock()
+			p.poll(dirs)
+		}
+	}
+}
+
+// poll은 직전 스캔과 비교해 사라진 파일은 Rename, 크기와 수정 시각이
+// 두 번 연속 같은 새 파일은 Create 이벤트로 전달한다.
+func (p *PollWatcher) poll(dirs []WatchDir) {
+	current := p.scan(dirs)
+
+	for path := range p.files {
+		if _, ok := current[path]; !ok {
+			p.core.handleRename(path)
+		}
+	}
+
+	for path, e := range current {
+		old, seen := p.files[path]
+		switch {
+		case !seen:
+			// 처음 본 파일은 쓰기가 끝났는지 다음 스캔에서 확인
+		case old.reported:
+			e.reported = true
+		case old.size == e.size && old.modTime.Equal(e.modTime):
+			e.reported = true
+			p.core.handleCreate(path)
+		}
+		current[path] = e
+	}
+
+	p.files = current
+}
+
+func (p *PollWatcher) scan(dirs []WatchDir) map[string]pollEntry {
+	files := make(map[string]pollEntry)
+	for _, d := range dirs {
+		root := expandHome(d.Path)
+		filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				// 네트워크 끊김 등으로 읽지 못한 디렉토리는 이번 스캔에서 건너뜀
+				return nil
+			}
+			if entry.IsDir() {
+				if path != root && !p.core.watchesDir(d, path) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			files[path] = pollEntry{size: info.Size(), modTime: info.ModTime()}
+			return nil
+		})
+	}
+	return files
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPollWatcherPoll(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "Screenshot 2025-01-14 at 09.00.00.png")
+	os.WriteFile(existing, []byte("old"), 0644)
+
+	p := NewPollWatcher(&Config{ScreenshotDir: dir}, &sync.Mutex{}, nil)
+	if err := p.Start(); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	p.Stop()
+
+	dirs := []WatchDir{{Path: dir}}
+	claimed := func(path string) bool {
+		p.core.mu.Lock()
+		defer p.core.mu.Unlock()
+		return p.core.processing[path]
+	}
+
+	t.Run("existing file ignored", func(t *testing.T) {
+		p.poll(dirs)
+		if claimed(existing) {
+			t.Error("file present at start should not be processed")
+		}
+	})
+
+	shot := filepath.Join(dir, "Screenshot 2025-01-15 at 12.30.45.png")
+
+	t.Run("new file waits until stable", func(t *testing.T) {
+		os.WriteFile(shot, []byte("partial"), 0644)
+		p.poll(dirs)
+		if claimed(shot) {
+			t.Error("file seen once should not be processed yet")
+		}
+
+		// 다음 스캔 전에 크기가 바뀌면 계속 대기
+		os.WriteFile(shot, []byte("partial-and-more"), 0644)
+		p.poll(dirs)
+		if claimed(shot) {
+			t.Error("file still being written should not be processed")
+		}
+
+		p.poll(dirs)
+		if !claimed(shot) {
+			t.Error("stable file should be processed")
+		}
+	})
+
+	t.Run("removed file reported as rename", func(t *testing.T) {
+		os.Remove(existing)
+		p.poll(dirs)
+		p.core.mu.Lock()
+		_, ok := p.core.renamedFrom[existing]
+		p.core.mu.Unlock()
+		if !ok {
+			t.Error("disappeared file should be recorded as rename source")
+		}
+	})
+}
+
+func TestPollWatcherCorrection(t *testing.T) {
+	dir := t.TempDir()
+	p := NewPollWatcher(&Config{ScreenshotDir: dir, PollInterval: 5}, &sync.Mutex{}, nil)
+	if err := p.Start(); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	p.Stop()
+	// 이동은 사라진 스캔과 안정된 스캔 사이, 폴링 간격 두 번 안에 짝지어진다
+	if want := 10*time.Second + renamePairWindow; p.core.pairWindow != want {
+		t.Errorf("pairWindow = %v, want %v", p.core.pairWindow, want)
+	}
+
+	got := make(chan string, 1)
+	p.core.onCorrected = func(_ Config, _ RenameResult, path string) { got <- path }
+
+	shot := filepath.Join(dir, "Screenshot 2025-01-15 at 12.30.45.png")
+	output := filepath.Join(dir, "2025-01-15_slack-chat.png")
+	corrected := filepath.Join(dir, "2025-01-15_Slack-chat.png")
+	os.WriteFile(output, []byte("png"), 0644)
+	dirs := []WatchDir{{Path: dir}}
+	p.poll(dirs)
+	p.Track(RenameResult{OriginalPath: shot, NewPath: output, Success: true})
+
+	os.Rename(output, corrected)
+	p.poll(dirs)
+	// 다음 스캔까지 1초 넘게 걸려도 같은 파일이면 수정으로 본다
+	p.core.mu.Lock()
+	p.core.renamedFrom[output] = time.Now().Add(-3 * time.Second)
+	p.core.mu.Unlock()
+	p.poll(dirs)
+
+	select {
+	case path := <-got:
+		if path != corrected {
+			t.Errorf("corrected path = %q, want %q", path, corrected)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("onCorrected not called")
+	}
+}
+
+func TestPollWatcherSkipsDuplicates(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "duplicates"), 0755)
+	moved := filepath.Join(root, "duplicates", "Screenshot 2025-01-15 at 12.30.45.png")
+	os.WriteFile(moved, []byte("png"), 0644)
+	shot := filepath.Join(root, "Screenshot 2025-01-15 at 12.31.00.png")
+	os.WriteFile(shot, []byte("png"), 0644)
+
+	cfg := &Config{WatchDirs: []WatchDir{{Path: root, Recursive: true}}, DuplicateAction: DuplicateMove}
+	p := NewPollWatcher(cfg, &sync.Mutex{}, nil)
+	files := p.scan(cfg.Dirs())
+	if _, ok := files[moved]; ok {
+		t.Error("files in the duplicates folder should not be scanned")
+	}
+	if _, ok := files[shot]; !ok {
+		t.Error("screenshot in the watched directory should be scanned")
+	}
+}
+
+func TestPollWatcherStartMissingDir(t *testing.T) {
+	p := NewPollWatcher(&Config{ScreenshotDir: filepath.Join(t.TempDir(), "missing")}, &sync.Mutex{}, nil)
+	if err := p.Start(); err == nil {
+		p.Stop()
+		t.Error("Start() should fail for missing directory")
+	}
+}
+
+func TestPollWatcherStartPartial(t *testing.T) {
+	good := t.TempDir()
+	existing := filepath.Join(good, "Screenshot 2025-01-15 at 12.30.45.png")
+	os.WriteFile(existing, []byte("png"), 0644)
+	missing := filepath.Join(t.TempDir(), "missing")
+	cfg := &Config{WatchDirs: []WatchDir{{Path: missing}, {Path: good}}}
+
+	p := NewPollWatcher(cfg, &sync.Mutex{}, nil)
+	err := p.Start()
+	if err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("Start() error = %v, want error for %s", err, missing)
+	}
+	defer p.Stop()
+	if _, ok := p.files[existing]; !ok {
+		t.Error("remaining directory should still be watched")
+	}
+}
+
+func TestNewFileWatcherMode(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		mode     WatchMode
+		wantPoll bool
+	}{
+		{"poll", WatchModePoll, true},
+		{"fsnotify", WatchModeNotify, false},
+		{"auto on local disk", WatchModeAuto, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fw, err := NewFileWatcher(&Config{ScreenshotDir: dir, WatchMode: tt.mode}, &sync.Mutex{}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, isPoll := fw.(*PollWatcher)
+			if isPoll != tt.wantPoll {
+				t.Errorf("NewFileWatcher(%q) polling = %v, want %v", tt.mode, isPoll, tt.wantPoll)
+			}
+			if w, ok := fw.(*Watcher); ok {
+				w.Stop()
+			}
+		})
+	}
+}
+
+func TestPollWatcherLoopStops(t *testing.T) {
+	p := NewPollWatcher(&Config{ScreenshotDir: t.TempDir(), PollInterval: 1}, &sync.Mutex{}, nil)
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		p.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Stop() should not block")
+	}
+}
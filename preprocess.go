@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"os"
+)
+
+const (
+	// AI 분석용 이미지의 긴 변 최대 길이 (픽셀)
+	defaultPreprocessMaxDim = 1568
+	// AI 분석용 JPEG 품질
+	defaultPreprocessQuality = 85
+	// 이보다 작고 크기 제한 안에 있는 이미지는 원본을 그대로 보낸다
+	preprocessMinBytes = 512 * 1024
+)
+
+// preprocessImage는 AI 분석에 보낼 축소/재압축 사본을 임시 파일로 만든다.
+// 리네이밍 대상은 항상 원본이다. 사본이 필요 없거나 만들 수 없으면 원본 경로를 반환한다.
+// 반환된 cleanup은 항상 호출해야 한다.
+func preprocessImage(cfg Config, imagePath string) (path string, cleanup func(), err error) {
+	noop := func() {}
+	if !cfg.PreprocessEnabled() {
+		return imagePath, noop, nil
+	}
+
+	info, err := os.Stat(imagePath)
+	if err != nil {
+		return imagePath, noop, err
+	}
+
+	f, err := os.Open(imagePath)
+	if err != nil {
+		return imagePath, noop, err
+	}
+	img, _, err := image.Decode(f)
+	f.Close()
+	if err != nil {
+		return imagePath, noop, err
+	}
+
+	maxDim := cfg.PreprocessMaxDim
+	if maxDim <= 0 {
+		maxDim = defaultPreprocessMaxDim
+	}
+	b := img.Bounds()
+	if max(b.Dx(), b.Dy()) <= maxDim && info.Size() < preprocessMinBytes {
+		return imagePath, noop, nil
+	}
+
+	tmp, err := os.CreateTemp("", "auto-naming-capture-*.jpg")
+	if err != nil {
+		return imagePath, noop, err
+	}
+	cleanup = func() { os.Remove(tmp.Name()) }
+
+	if err := encodePreprocessed(tmp, img, maxDim, cfg.PreprocessQuality); err != nil {
+		tmp.Close()
+		cleanup()
+		return imagePath, noop, err
+	}
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return imagePath, noop, err
+	}
+	return tmp.Name(), cleanup, nil
+}
+
+// encodePreprocessed는 이미지를 maxDim 이하로 축소하고 JPEG로 인코딩한다.
+// 같은 입력이면 항상 같은 바이트를 출력한다.
+func encodePreprocessed(w *os.File, img image.Image, maxDim, quality int) error {
+	if quality <= 0 || quality > 100 {
+		quality = defaultPreprocessQuality
+	}
+	if err := jpeg.Encode(w, downscale(img, maxDim), &jpeg.Options{Quality: quality}); err != nil {
+		return fmt.Errorf("encode preprocessed image: %w", err)
+	}
+	return nil
+}
+
+// downscale은 긴 변이 maxDim 이하가 되도록 면적 평균(box filter)으로 축소한다.
+// 투명 영역은 JPEG에 맞게 흰 배경 위에 합성한다.
+func downscale(img image.Image, maxDim int) *image.RGBA {
+	b := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Over)
+
+	sw, sh := b.Dx(), b.Dy()
+	if max(sw, sh) <= maxDim {
+		return src
+	}
+	dw, dh := sw*maxDim/max(sw, sh), sh*maxDim/max(sw, sh)
+	dw, dh = max(dw, 1), max(dh, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		y0, y1 := dy*sh/dh, max((dy+1)*sh/dh, dy*sh/dh+1)
+		for dx := 0; dx < dw; dx++ {
+			x0, x1 := dx*sw/dw, max((dx+1)*sw/dw, dx*sw/dw+1)
+			var r, g, bl, n int
+			for y := y0; y < y1; y++ {
+				off := src.PixOffset(x0, y)
+				for x := x0; x < x1; x++ {
+					r += int(src.Pix[off])
+					g += int(src.Pix[off+1])
+					bl += int(src.Pix[off+2])
+					off += 4
+					n++
+				}
+			}
+			o := dst.PixOffset(dx, dy)
+			dst.Pix[o] = uint8(r / n)
+			dst.Pix[o+1] = uint8(g / n)
+			dst.Pix[o+2] = uint8(bl / n)
+			dst.Pix[o+3] = 0xff
+		}
+	}
+	return dst
+}
+
+// fileSizeSummary는 원본과 사본의 파일 크기를 "5.2MB → 310KB" 형식으로 보여준다.
+func fileSizeSummary(original, preprocessed string) string {
+	size := func(path string) string {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "?"
+		}
+		n := float64(info.Size())
+		switch {
+		case n >= 1<<20:
+			return fmt.Sprintf("%.1fMB", n/(1<<20))
+		case n >= 1<<10:
+			return fmt.Sprintf("%.0fKB", n/(1<<10))
+		default:
+			return fmt.Sprintf("%dB", info.Size())
+		}
+	}
+	return size(original) + " → " + size(preprocessed)
+}
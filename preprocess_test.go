@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "testdata의 golden 파일 갱신")
+
+// writeLargePNG는 색 격자 무늬 PNG를 만든다 (압축이 잘 안 되도록 노이즈 포함).
+func writeLargePNG(t *testing.T, path string, w, h int) {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			n := uint8((x*7919 + y*104729) % 251)
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 255 / w), G: uint8(y * 255 / h), B: n, A: 255})
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDownscale(t *testing.T) {
+	tests := []struct {
+		name         string
+		w, h, maxDim int
+		wantW, wantH int
+	}{
+		{"landscape", 3000, 2000, 1500, 1500, 1000},
+		{"portrait", 1000, 4000, 1000, 250, 1000},
+		{"already small", 800, 600, 1568, 800, 600},
+		{"thin strip", 5000, 2, 100, 100, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := downscale(image.NewGray(image.Rect(0, 0, tt.w, tt.h)), tt.maxDim)
+			if b := got.Bounds(); b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+				t.Errorf("downscale = %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestDownscaleTransparentOnWhite(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	got := downscale(img, 2)
+	if c := got.RGBAAt(0, 0); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("transparent pixel = %v, want white", c)
+	}
+}
+
+func TestPreprocessImage(t *testing.T) {
+	dir := t.TempDir()
+	large := filepath.Join(dir, "large.png")
+	small := filepath.Join(dir, "small.png")
+	writeLargePNG(t, large, 2000, 1200)
+	writeLargePNG(t, small, 40, 30)
+	disabled := false
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		path     string
+		wantCopy bool
+		wantDims [2]int
+	}{
+		{"large image downscaled", Config{PreprocessMaxDim: 1000}, large, true, [2]int{1000, 600}},
+		{"default max dimension", Config{}, large, true, [2]int{1568, 940}},
+		{"small image sent as is", Config{}, small, false, [2]int{}},
+		{"disabled", Config{Preprocess: &disabled}, large, false, [2]int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, cleanup, err := preprocessImage(tt.cfg, tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.wantCopy {
+				cleanup()
+				if got != tt.path {
+					t.Errorf("preprocessImage = %q, want original", got)
+				}
+				return
+			}
+			if got == tt.path {
+				t.Fatal("preprocessImage returned original, want copy")
+			}
+
+			f, err := os.Open(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg, format, err := image.DecodeConfig(f)
+			f.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != "jpeg" || cfg.Width != tt.wantDims[0] || cfg.Height != tt.wantDims[1] {
+				t.Errorf("copy = %s %dx%d, want jpeg %dx%d", format, cfg.Width, cfg.Height, tt.wantDims[0], tt.wantDims[1])
+			}
+			if _, err := os.Stat(tt.path); err != nil {
+				t.Errorf("original missing: %v", err)
+			}
+
+			cleanup()
+			if _, err := os.Stat(got); !os.IsNotExist(err) {
+				t.Errorf("copy not removed after cleanup: %v", err)
+			}
+		})
+	}
+}
+
+func TestPreprocessImageInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.png")
+	if err := os.WriteFile(path, []byte("not an image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, cleanup, err := preprocessImage(Config{}, path)
+	defer cleanup()
+	if err == nil || got != path {
+		t.Errorf("preprocessImage = %q, %v, want original path and error", got, err)
+	}
+}
+
+// 같은 입력은 항상 같은 바이트를 만들어야 한다 (go test -run Golden -update로 갱신)
+func TestPreprocessGolden(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "input.png")
+	writeLargePNG(t, src, 640, 400)
+
+	got, cleanup, err := preprocessImage(Config{PreprocessMaxDim: 64, PreprocessQuality: 80}, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	data, err := os.ReadFile(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "preprocess_64q80.jpg")
+	if *updateGolden {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("preprocessed output differs from %s (%d bytes, want %d)", golden, len(data), len(want))
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("golden output is not a valid jpeg: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Preset은 캡처 도구/로케일별 스크린샷 파일명 규칙이다.
+// 촬영 시각은 정규식의 이름 있는 그룹(year, month, day, hour, minute, second, ampm)으로 추출한다.
+type Preset struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// macOS 시각 표기: "12.30.45", 12시간제면 AM/PM (앞에 좁은 공백이 올 수 있음)
+const (
+	macDate = `(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})`
+	macTime = `(?P<hour>\d{1,2})\.(?P<minute>\d{2})\.(?P<second>\d{2})`
+	imgExt  = `\.(png|jpg|jpeg)$`
+)
+
+var presets = []Preset{
+	{"macos-en", regexp.MustCompile(`^Screenshot ` + macDate + `(?: at ` + macTime + `(?:[ \x{202F}]?(?P<ampm>AM|PM))?)?.*` + imgExt)},
+	{"macos-ko", regexp.MustCompile(`^스크린샷 ` + macDate + `(?: (?:(?P<ampm>오전|오후) )?` + macTime + `)?.*` + imgExt)},
+	{"macos-ja", regexp.MustCompile(`^スクリーンショット ` + macDate + `(?: (?P<ampm>午前|午後)?` + macTime + `)?.*` + imgExt)},
+	{"macos-de", regexp.MustCompile(`^Bildschirmfoto ` + macDate + `(?: um ` + macTime + `)?.*` + imgExt)},
+	{"macos-fr", regexp.MustCompile(`^Capture d[’']écran ` + macDate + `(?: à ` + macTime + `)?.*` + imgExt)},
+	{"gnome", regexp.MustCompile(`^Screenshot [Ff]rom ` + macDate + ` (?P<hour>\d{2})-(?P<minute>\d{2})-(?P<second>\d{2}).*` + imgExt)},
+	{"kde-spectacle", regexp.MustCompile(`^Screenshot_(?P<year>\d{4})(?P<month>\d{2})(?P<day>\d{2})_(?P<hour>\d{2})(?P<minute>\d{2})(?P<second>\d{2}).*` + imgExt)},
+	{"flameshot", regexp.MustCompile(`^` + macDate + `_(?P<hour>\d{2})-(?P<minute>\d{2})(?:-(?P<second>\d{2}))?(?:_\d+)?` + imgExt)},
+	{"windows-snip", regexp.MustCompile(`^Screenshot ` + macDate + ` (?P<hour>\d{2})(?P<minute>\d{2})(?P<second>\d{2}).*` + imgExt)},
+	{"cleanshot", regexp.MustCompile(`^CleanShot ` + macDate + `(?: at ` + macTime + `(?:[ \x{202F}]?(?P<ampm>AM|PM))?)?.*` + imgExt)},
+	{"shottr", regexp.MustCompile(`^SCR-(?P<year>\d{4})(?P<month>\d{2})(?P<day>\d{2})-[a-z0-9]+.*` + imgExt)},
+}
+
+// 설정이 없을 때 사용하는 프리셋 (영어/한국어 macOS)
+var defaultPresets = []string{"macos-en", "macos-ko"}
+
+var pmMarkers = map[string]bool{"PM": true, "오후": true, "午後": true}
+
+func presetByName(name string) (Preset, bool) {
+	for _, p := range presets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// compilePatterns는 프리셋 이름과 사용자 정규식을 패턴 목록으로 만든다.
+// 알 수 없는 프리셋이나 잘못된 정규식은 건너뛰고, 유효한 패턴이 없으면 기본 프리셋을 사용한다.
+func compilePatterns(presetNames, patterns []string) []*regexp.Regexp {
+	var compiled []*regexp.Regexp
+	for _, name := range presetNames {
+		p, ok := presetByName(name)
+		if !ok {
+			configLog.Warn("알 수 없는 프리셋", "preset", name)
+			continue
+		}
+		compiled = append(compiled, p.Pattern)
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			configLog.Warn("잘못된 패턴", "pattern", p, "err", err)
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	if len(compiled) == 0 {
+		return defaultPatterns()
+	}
+	return compiled
+}
+
+func defaultPatterns() []*regexp.Regexp {
+	var compiled []*regexp.Regexp
+	for _, name := range defaultPresets {
+		p, _ := presetByName(name)
+		compiled = append(compiled, p.Pattern)
+	}
+	return compiled
+}
+
+// screenshotTime은 일치하는 패턴의 이름 있는 그룹에서 촬영 시각을 추출한다.
+// 날짜 그룹이 없는 패턴이거나 일치하는 패턴이 없으면 false를 반환한다.
+func screenshotTime(filename string, patterns []*regexp.Regexp) (time.Time, bool) {
+	for _, re := range patterns {
+		m := re.FindStringSubmatch(filename)
+		if m == nil {
+			continue
+		}
+		groups := make(map[string]string)
+		for i, name := range re.SubexpNames() {
+			if name != "" && m[i] != "" {
+				groups[name] = m[i]
+			}
+		}
+		if t, ok := timeFromGroups(groups); ok {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func timeFromGroups(groups map[string]string) (time.Time, bool) {
+	num := func(name string) (int, bool) {
+		v, ok := groups[name]
+		if !ok {
+			return 0, false
+		}
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	}
+
+	year, okY := num("year")
+	month, okM := num("month")
+	day, okD := num("day")
+	if !okY || !okM || !okD {
+		return time.Time{}, false
+	}
+	hour, _ := num("hour")
+	minute, _ := num("minute")
+	second, _ := num("second")
+	if ampm, ok := groups["ampm"]; ok {
+		// 12시간제: 오후 1시 → 13시, 오전 12시 → 0시
+		hour %= 12
+		if pmMarkers[ampm] {
+			hour += 12
+		}
+	}
+
+	t := time.Date(year, time.Month(month), day, hour, minute, second, 0, time.Local)
+	// 13월, 25시 같은 값은 time.Date가 정규화하므로 되돌려 확인
+	if t.Month() != time.Month(month) || t.Day() != day || t.Hour() != hour || t.Minute() != minute {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPresets(t *testing.T) {
+	tests := []struct {
+		preset   string
+		filename string
+		want     time.Time
+	}{
+		{"macos-en", "Screenshot 2025-01-15 at 12.30.45.png", time.Date(2025, 1, 15, 12, 30, 45, 0, time.Local)},
+		{"macos-en", "Screenshot 2025-01-15 at 1.30.45 PM.png", time.Date(2025, 1, 15, 13, 30, 45, 0, time.Local)},
+		{"macos-en", "Screenshot 2025-01-15 at 12.05.00 AM.png", time.Date(2025, 1, 15, 0, 5, 0, 0, time.Local)},
+		{"macos-ko", "스크린샷 2025-01-15 오후 3.30.45.png", time.Date(2025, 1, 15, 15, 30, 45, 0, time.Local)},
+		{"macos-ko", "스크린샷 2025-01-15 오전 9.00.00.jpg", time.Date(2025, 1, 15, 9, 0, 0, 0, time.Local)},
+		{"macos-ko", "스크린샷 2025-06-30 12.30.45.png", time.Date(2025, 6, 30, 12, 30, 45, 0, time.Local)},
+		{"macos-ja", "スクリーンショット 2025-01-15 午後3.30.45.png", time.Date(2025, 1, 15, 15, 30, 45, 0, time.Local)},
+		{"macos-ja", "スクリーンショット 2025-01-15 12.30.45.png", time.Date(2025, 1, 15, 12, 30, 45, 0, time.Local)},
+		{"macos-de", "Bildschirmfoto 2025-01-15 um 12.30.45.png", time.Date(2025, 1, 15, 12, 30, 45, 0, time.Local)},
+		{"macos-fr", "Capture d’écran 2025-01-15 à 12.30.45.png", time.Date(2025, 1, 15, 12, 30, 45, 0, time.Local)},
+		{"macos-fr", "Capture d'écran 2025-01-15 à 12.30.45.png", time.Date(2025, 1, 15, 12, 30, 45, 0, time.Local)},
+		{"gnome", "Screenshot from 2025-01-15 12-30-45.png", time.Date(2025, 1, 15, 12, 30, 45, 0, time.Local)},
+		{"gnome", "Screenshot From 2025-01-15 12-30-45.png", time.Date(2025, 1, 15, 12, 30, 45, 0, time.Local)},
+		{"kde-spectacle", "Screenshot_20250115_123045.png", time.Date(2025, 1, 15, 12, 30, 45, 0, time.Local)},
+		{"flameshot", "2025-01-15_12-30.png", time.Date(2025, 1, 15, 12, 30, 0, 0, time.Local)},
+		{"flameshot", "2025-01-15_12-30_1.png", time.Date(2025, 1, 15, 12, 30, 0, 0, time.Local)},
+		{"windows-snip", "Screenshot 2025-01-15 123045.png", time.Date(2025, 1, 15, 12, 30, 45, 0, time.Local)},
+		{"cleanshot", "CleanShot 2025-01-15 at 12.30.45@2x.png", time.Date(2025, 1, 15, 12, 30, 45, 0, time.Local)},
+		{"shottr", "SCR-20250115-ixog.png", time.Date(2025, 1, 15, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset+"/"+tt.filename, func(t *testing.T) {
+			patterns := compilePatterns([]string{tt.preset}, nil)
+			got, ok := screenshotTime(tt.filename, patterns)
+			if !ok {
+				t.Fatalf("%s preset should match %q", tt.preset, tt.filename)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("screenshotTime(%q) = %v, want %v", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPresetsDoNotMatch(t *testing.T) {
+	tests := []struct {
+		preset   string
+		filename string
+	}{
+		// 리네이밍된 결과물은 다시 매칭되면 안 됨
+		{"flameshot", "2025-01-15_슬랙-대화.png"},
+		{"flameshot", "2025-01-15_github-pr-review.png"},
+		{"gnome", "Screenshot 2025-01-15 at 12.30.45.png"},
+		{"kde-spectacle", "Screenshot_2025-01-15.png"},
+		{"shottr", "SCR-20250115.png"},
+		{"macos-de", "Bildschirmfoto 2025-01-15 um 12.30.45.gif"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset+"/"+tt.filename, func(t *testing.T) {
+			if matchesPatterns(tt.filename, compilePatterns([]string{tt.preset}, nil)) {
+				t.Errorf("%s preset should not match %q", tt.preset, tt.filename)
+			}
+		})
+	}
+}
+
+func TestCompilePatterns(t *testing.T) {
+	t.Run("defaults when empty", func(t *testing.T) {
+		if got := compilePatterns(nil, nil); len(got) != len(defaultPresets) {
+			t.Errorf("len = %d, want %d default patterns", len(got), len(defaultPresets))
+		}
+	})
+
+	t.Run("unknown preset and invalid regex fall back", func(t *testing.T) {
+		got := compilePatterns([]string{"no-such-tool"}, []string{"("})
+		if len(got) != len(defaultPresets) {
+			t.Errorf("len = %d, want %d default patterns", len(got), len(defaultPresets))
+		}
+	})
+
+	t.Run("presets and custom patterns combined", func(t *testing.T) {
+		got := compilePatterns([]string{"gnome"}, []string{`^capture-\d+\.png$`})
+		if len(got) != 2 {
+			t.Fatalf("len = %d, want 2", len(got))
+		}
+		if !matchesPatterns("capture-1.png", got) || !matchesPatterns("Screenshot from 2025-01-15 12-30-45.png", got) {
+			t.Error("combined patterns should match both preset and custom names")
+		}
+	})
+
+	t.Run("custom pattern without timestamp groups", func(t *testing.T) {
+		if _, ok := screenshotTime("capture-1.png", compilePatterns(nil, []string{`^capture-\d+\.png$`})); ok {
+			t.Error("pattern without date groups should not yield a timestamp")
+		}
+	})
+
+	t.Run("invalid date rejected", func(t *testing.T) {
+		if _, ok := screenshotTime("Screenshot 2025-13-45 at 12.30.45.png", defaultPatterns()); ok {
+			t.Error("month 13 should not parse")
+		}
+	})
+}
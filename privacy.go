@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// PrivacyAction은 privacy 규칙에 걸린 스크린샷을 어떻게 처리할지 정한다.
+type PrivacyAction string
+
+const (
+	// 프로바이더를 부르지 않고 규칙 이름으로 리네이밍
+	PrivacyLocal PrivacyAction = "local"
+	// 파일을 건드리지 않음
+	PrivacySkip PrivacyAction = "skip"
+)
+
+// 규칙 이름이 없을 때 쓰는 로컬 파일명
+const defaultPrivateName = "private"
+
+// errPrivacySkip은 privacy 규칙에 따라 파일을 그대로 두었음을 나타낸다.
+var errPrivacySkip = errors.New("left untouched by privacy rule")
+
+// PrivacyRule은 클라우드 모델에 보내지 않을 스크린샷 조건이다.
+// 조건 중 하나라도 맞으면 "local only"로 처리한다.
+type PrivacyRule struct {
+	Name string `json:"name"`
+	// OCR 텍스트에 포함되면 맞는 키워드 (대소문자 무시)
+	Keywords []string `json:"keywords,omitempty"`
+	// 감시자가 파일을 감지한 시점의 맨 앞 앱 이름 (대소문자 무시, 부분 일치)
+	Apps []string `json:"apps,omitempty"`
+	// OCR 텍스트 정규식
+	Patterns []string      `json:"patterns,omitempty"`
+	Action   PrivacyAction `json:"action,omitempty"`
+}
+
+// localName은 규칙에 걸린 스크린샷에 붙일 이름이다.
+func (r PrivacyRule) localName(maxLen int) string {
+	if r.Name == "" {
+		return defaultPrivateName
+	}
+	return SanitizeFilename(r.Name, maxLen)
+}
+
+// privacyNeedsOCR은 키워드/정규식 규칙이 있어서 OCR 정책과 관계없이 OCR이 필요한지 여부다.
+// OCR은 로컬에서만 수행되므로 규칙 판단을 위해 항상 돌린다.
+func privacyNeedsOCR(cfg Config) bool {
+	for _, r := range cfg.PrivacyRules {
+		if len(r.Keywords) > 0 || len(r.Patterns) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// privacyNeedsApp은 맨 앞 앱 조건이 있는 규칙이 있는지 여부다.
+func privacyNeedsApp(cfg Config) bool {
+	for _, r := range cfg.PrivacyRules {
+		if len(r.Apps) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPrivacyRule은 OCR 텍스트와 맨 앞 앱 app으로 첫 번째로 맞는 규칙과 그 이유를 찾는다.
+// OCR이 실패했으면(ocrErr) 텍스트를 확인할 수 없으므로 키워드/정규식 규칙은 맞는 것으로 본다.
+func matchPrivacyRule(cfg Config, ocrResult OCRResult, ocrErr error, app string) (*PrivacyRule, string) {
+	text := strings.ToLower(ocrResult.Text)
+	app = strings.ToLower(app)
+
+	for i := range cfg.PrivacyRules {
+		r := &cfg.PrivacyRules[i]
+		if ocrErr != nil && (len(r.Keywords) > 0 || len(r.Patterns) > 0) {
+			return r, fmt.Sprintf("OCR failed: %v", ocrErr)
+		}
+		for _, kw := range r.Keywords {
+			if kw != "" && strings.Contains(text, strings.ToLower(kw)) {
+				return r, fmt.Sprintf("keyword %q", kw)
+			}
+		}
+		for _, p := range r.Patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				configLog.Warn("잘못된 privacy 패턴", "rule", r.Name, "pattern", p, "err", err)
+				continue
+			}
+			if re.MatchString(ocrResult.Text) {
+				return r, fmt.Sprintf("pattern %q", p)
+			}
+		}
+		for _, a := range r.Apps {
+			if app != "" && a != "" && strings.Contains(app, strings.ToLower(a)) {
+				return r, fmt.Sprintf("app %q", a)
+			}
+		}
+	}
+	return nil, ""
+}
+
+// frontmostApp은 지금 사용 중인(맨 앞) 앱 이름이다. 감시자가 파일을 감지하자마자 불러
+// 캡처 시점에 가깝게 기록한다 (폴링 감시는 최대 두 번의 폴링 간격만큼 늦다).
+// 알 수 없으면 빈 문자열을 반환한다. 테스트에서 교체한다.
+var frontmostApp = func() string {
+	if runtime.GOOS != "darwin" {
+		return ""
+	}
+	out, err := exec.Command("osascript", "-e",
+		`tell application "System Events" to get name of first application process whose frontmost is true`).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
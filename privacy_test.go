@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMatchPrivacyRule(t *testing.T) {
+	rules := []PrivacyRule{
+		{Name: "banking", Keywords: []string{"인터넷뱅킹", "Account Balance"}},
+		{Name: "hr", Patterns: []string{`(?i)payroll|급여명세`, `(`}},
+		{Name: "passwords", Apps: []string{"1password", "Keychain Access"}, Action: PrivacySkip},
+	}
+	tests := []struct {
+		name     string
+		rules    []PrivacyRule
+		text     string
+		ocrErr   error
+		app      string
+		wantRule string
+		wantWhy  string
+	}{
+		{"keyword case insensitive", rules[:1], "Your account balance is", nil, "", "banking", `keyword "Account Balance"`},
+		{"korean keyword", rules[:1], "KB 인터넷뱅킹 로그인", nil, "", "banking", `keyword "인터넷뱅킹"`},
+		{"pattern", rules[1:2], "2026년 10월 급여명세서", nil, "", "hr", `pattern "(?i)payroll|급여명세"`},
+		{"frontmost app", rules[2:], "", nil, "1Password 8", "passwords", `app "1password"`},
+		{"unknown app", rules[2:], "", nil, "", "", ""},
+		{"first matching rule wins", rules, "Payroll 인터넷뱅킹", nil, "1Password 8", "banking", `keyword "인터넷뱅킹"`},
+		{"no match", rules[:2], "Slack #general", nil, "1Password 8", "", ""},
+		{"no rules", nil, "인터넷뱅킹", nil, "", "", ""},
+
+		// OCR이 실패하면 텍스트 규칙은 걸린 것으로 본다
+		{"ocr failed", rules[:2], "", errors.New("timeout"), "", "banking", "OCR failed: timeout"},
+		{"ocr failed app rule", rules[2:], "", errors.New("timeout"), "Slack", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, why := matchPrivacyRule(Config{PrivacyRules: tt.rules}, OCRResult{Text: tt.text, HasText: tt.text != ""}, tt.ocrErr, tt.app)
+			var got string
+			if rule != nil {
+				got = rule.Name
+			}
+			if got != tt.wantRule || why != tt.wantWhy {
+				t.Errorf("matchPrivacyRule = %q (%s), want %q (%s)", got, why, tt.wantRule, tt.wantWhy)
+			}
+		})
+	}
+}
+
+func TestPrivacyNeedsOCR(t *testing.T) {
+	tests := []struct {
+		rules []PrivacyRule
+		want  bool
+	}{
+		{nil, false},
+		{[]PrivacyRule{{Apps: []string{"1Password"}}}, false},
+		{[]PrivacyRule{{Keywords: []string{"bank"}}}, true},
+		{[]PrivacyRule{{Patterns: []string{"payroll"}}}, true},
+	}
+	for _, tt := range tests {
+		if got := privacyNeedsOCR(Config{PrivacyRules: tt.rules}); got != tt.want {
+			t.Errorf("privacyNeedsOCR(%+v) = %v, want %v", tt.rules, got, tt.want)
+		}
+	}
+}
+
+func TestProcessScreenshotPrivacy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tesseract, _ := fakeCLI(t, sampleTSV)
+
+	tests := []struct {
+		name     string
+		rule     PrivacyRule
+		wantPath string
+		skipped  bool
+	}{
+		{"local name", PrivacyRule{Name: "Private Notes", Keywords: []string{"hello"}}, "2025-01-15_Private-Notes.png", false},
+		{"unnamed rule", PrivacyRule{Keywords: []string{"hello"}}, "2025-01-15_private.png", false},
+		{"skip leaves file untouched", PrivacyRule{Name: "secret", Patterns: []string{`second\s+line`}, Action: PrivacySkip}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			shot := writeTestPNG(t, dir, "Screenshot 2025-01-15 at 12.30.45.png", true)
+			claude, argsFile := fakeCLI(t, "cloud-name")
+			cfg := Config{
+				Provider:       ProviderClaude,
+				ClaudePath:     claude,
+				MaxFileNameLen: 80,
+				OCREngine:      OCREngineTesseract,
+				TesseractPath:  tesseract,
+				OCRPolicy:      OCRPolicyNever,
+				PrivacyRules:   []PrivacyRule{tt.rule},
+			}
+
+			result := ProcessScreenshot(cfg, shot)
+			if !result.OCRRan {
+				t.Error("OCR should run for keyword rules even with policy never")
+			}
+			if result.LocalOnly != tt.rule.Name || result.LocalOnlyReason == "" {
+				t.Errorf("LocalOnly = %q (%s), want rule %q", result.LocalOnly, result.LocalOnlyReason, tt.rule.Name)
+			}
+			if _, err := os.Stat(argsFile); err == nil {
+				t.Error("provider must not be called for local-only screenshots")
+			}
+			if result.Skipped != tt.skipped || result.Error != nil {
+				t.Errorf("Skipped = %v, Error = %v, want skipped %v", result.Skipped, result.Error, tt.skipped)
+			}
+			if tt.skipped {
+				if _, err := os.Stat(shot); err != nil {
+					t.Errorf("original should be untouched: %v", err)
+				}
+				return
+			}
+			if want := filepath.Join(dir, tt.wantPath); result.NewPath != want {
+				t.Errorf("NewPath = %q, want %q", result.NewPath, want)
+			}
+		})
+	}
+}
+
+func TestProcessScreenshotPrivacyOCRFailed(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	shot := writeTestPNG(t, dir, "Screenshot 2025-01-15 at 12.30.45.png", true)
+	claude, argsFile := fakeCLI(t, "cloud-name")
+	cfg := Config{
+		Provider:       ProviderClaude,
+		ClaudePath:     claude,
+		MaxFileNameLen: 80,
+		OCREngine:      OCREngineTesseract,
+		TesseractPath:  filepath.Join(t.TempDir(), "missing"),
+		PrivacyRules:   []PrivacyRule{{Name: "banking", Keywords: []string{"인터넷뱅킹"}}},
+	}
+
+	result := ProcessScreenshot(cfg, shot)
+	if result.LocalOnly != "banking" || !strings.HasPrefix(result.LocalOnlyReason, "OCR failed") {
+		t.Errorf("LocalOnly = %q (%s), want banking because OCR failed", result.LocalOnly, result.LocalOnlyReason)
+	}
+	if _, err := os.Stat(argsFile); err == nil {
+		t.Error("provider must not be called when privacy rules cannot be checked")
+	}
+	if !result.Success || result.NewPath != filepath.Join(dir, "2025-01-15_banking.png") {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestWatcherCaptureApp(t *testing.T) {
+	orig := frontmostApp
+	t.Cleanup(func() { frontmostApp = orig })
+	calls := 0
+	frontmostApp = func() string { calls++; return "1Password 8" }
+
+	dir := t.TempDir()
+	cfg := &Config{ScreenshotDir: dir}
+	w := newWatcher(cfg, &sync.Mutex{}, nil, nil)
+	if app := w.captureApp(filepath.Join(dir, "shot.png")); app != "" || calls != 0 {
+		t.Errorf("captureApp without app rules = %q (%d calls), want no lookup", app, calls)
+	}
+	cfg.PrivacyRules = []PrivacyRule{{Name: "passwords", Apps: []string{"1Password"}}}
+	if app := w.captureApp(filepath.Join(dir, "shot.png")); app != "1Password 8" {
+		t.Errorf("captureApp = %q, want frontmost app", app)
+	}
+}
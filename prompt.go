@@ -0,0 +1,196 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// 프롬프트 템플릿 이름. 설정 디렉토리의 prompts/<이름>.tmpl이 있으면 기본값 대신 쓴다.
+const (
+	promptSystem = "system"
+	promptClaude = "claude"
+	promptCodex  = "codex"
+)
+
+// OCR 텍스트는 눈에 띄는 줄부터 이 길이까지만 프롬프트에 넣는다
+const maxPromptOCRRunes = 500
+
+const claudePromptTemplate = `이미지 경로: {{.ImagePath}}
+이미지를 분석하고 파일명을 생성해줘.{{if .HasText}}
+
+{{.OCR}}{{end}}`
+
+const codexPromptTemplate = `{{.System}}
+
+---
+
+첨부된 이미지를 분석하고 파일명을 생성해줘.{{if .HasText}}
+
+{{.OCR}}{{end}}`
+
+// 템플릿에서 쓸 수 있는 함수
+var promptFuncs = template.FuncMap{
+	"truncate": truncate,
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"join":     strings.Join,
+}
+
+var builtinPrompts = map[string]*template.Template{
+	promptSystem: parsePrompt(promptSystem, systemPrompt),
+	promptClaude: parsePrompt(promptClaude, claudePromptTemplate),
+	promptCodex:  parsePrompt(promptCodex, codexPromptTemplate),
+}
+
+func parsePrompt(name, text string) *template.Template {
+	return template.Must(newPromptTemplate(name).Parse(text))
+}
+
+func newPromptTemplate(name string) *template.Template {
+	return template.New(name).Funcs(promptFuncs).Option("missingkey=error")
+}
+
+// PromptData는 프롬프트 템플릿에서 쓸 수 있는 값이다.
+type PromptData struct {
+	// 프로바이더가 보는 이미지 경로 (샌드박스 사본 등)
+	ImagePath string
+	// 원본 스크린샷 파일명과 디렉토리
+	Filename string
+	Dir      string
+	// 촬영 시각 ({{.CaptureTime.Format "2006-01-02"}})
+	CaptureTime time.Time
+	// 사용자 로케일 (예: ko-KR)
+	Locale string
+	// 민감 정보를 가리고 잘라낸 OCR 텍스트
+	OCRText string
+	// OCRText를 신뢰할 수 없는 데이터 구역으로 감싼 것 (프롬프트에는 이쪽을 권장)
+	OCR     string
+	HasText bool
+	// 강제할 파일명 언어 (빈 값이면 자유)와 그 이름 (예: 한국어)
+	Language     NameLanguage
+	LanguageName string
+	// 표기 방식 설명 (name_case가 없으면 빈 값)
+	CaseRule string
+	// 파일명 단어 수 범위
+	MinWords int
+	MaxWords int
+	// 용어집 (정식 표기와 별칭, 설정과 학습한 항목을 합친 것)
+	Glossary []GlossaryEntry
+	// 사용자가 직접 고친 이전 이름 중 OCR 텍스트가 비슷한 것 (few-shot 예시)
+	Examples []NameExample
+	// 요청할 파일명 후보 수 (1이면 파일명 하나)
+	Candidates int
+	// 렌더링된 시스템 프롬프트 (codex처럼 시스템 프롬프트를 따로 받지 않는 경우)
+	System string
+}
+
+// newPromptData는 원본 스크린샷과 (가린) OCR 결과로 템플릿 값을 만든다.
+func newPromptData(cfg Config, screenshotPath string, ocrResult OCRResult) PromptData {
+	filename := filepath.Base(screenshotPath)
+	data := PromptData{
+		ImagePath:   screenshotPath,
+		Filename:    filename,
+		Dir:         filepath.Dir(screenshotPath),
+		CaptureTime: captureTime(filename, cfg.ScreenshotPatterns()),
+		Locale:      promptLocale(cfg),
+		HasText:     ocrResult.HasText,
+		Language:    resolveNameLanguage(cfg, ocrResult),
+		CaseRule:    nameCaseRules[cfg.NameCase],
+		MinWords:    defaultPromptMinWords,
+		MaxWords:    defaultPromptMaxWords,
+		Glossary:    loadGlossary(cfg).Entries(),
+		Candidates:  cfg.Candidates(),
+	}
+	data.LanguageName = nameLanguageNames[data.Language]
+	if cfg.NameMinWords > 0 {
+		data.MinWords = cfg.NameMinWords
+	}
+	if cfg.NameMaxWords > 0 {
+		data.MaxWords = cfg.NameMaxWords
+	}
+	data.MinWords = min(data.MinWords, data.MaxWords)
+	if ocrResult.HasText {
+		data.OCRText = truncate(ocrResult.Prominent(), maxPromptOCRRunes)
+		data.OCR = untrustedSection("참고용 OCR 텍스트", data.OCRText)
+	}
+	data.Examples = selectExamples(cfg, data.OCRText)
+	return data
+}
+
+// promptLocale은 설정한 OCR 언어 중 첫 번째, 없으면 시스템 언어다.
+func promptLocale(cfg Config) string {
+	langs := cfg.OCRLanguages
+	if len(langs) == 0 {
+		langs = systemOCRLanguages()
+	}
+	if len(langs) == 0 {
+		return "en-US"
+	}
+	return langs[0]
+}
+
+func promptDir() string {
+	return filepath.Join(configDir(), "prompts")
+}
+
+func promptPath(name string) string {
+	return filepath.Join(promptDir(), name+".tmpl")
+}
+
+// renderPrompt는 사용자 템플릿(없으면 기본값)으로 프롬프트를 만든다.
+// 사용자 템플릿에 오류가 있으면 로그를 남기고 기본값을 쓴다.
+func renderPrompt(name string, data PromptData) string {
+	if text, err := os.ReadFile(promptPath(name)); err == nil {
+		tmpl, err := newPromptTemplate(name).Parse(string(text))
+		if err == nil {
+			var out string
+			if out, err = executePrompt(tmpl, data); err == nil {
+				return out
+			}
+		}
+		namerLog.Warn("프롬프트 템플릿 오류 - 기본 프롬프트 사용", "template", promptPath(name), "err", err)
+	}
+	out, err := executePrompt(builtinPrompts[name], data)
+	if err != nil {
+		namerLog.Error("기본 프롬프트 렌더링 실패", "template", name, "err", err)
+	}
+	return out
+}
+
+func executePrompt(tmpl *template.Template, data PromptData) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func buildSystemPrompt(data PromptData) string {
+	return renderPrompt(promptSystem, data)
+}
+
+func buildPrompt(data PromptData) string {
+	return renderPrompt(promptClaude, data)
+}
+
+func buildCodexPrompt(data PromptData) string {
+	data.System = buildSystemPrompt(data)
+	return renderPrompt(promptCodex, data)
+}
+
+// promptTemplatesVersion은 사용자 템플릿 내용의 해시다. 템플릿을 바꾸면 이름 캐시가 무효화된다.
+func promptTemplatesVersion() string {
+	h := sha256.New()
+	for _, name := range []string{promptSystem, promptClaude, promptCodex} {
+		if text, err := os.ReadFile(promptPath(name)); err == nil {
+			fmt.Fprintf(h, "%s\x00%s\x00", name, text)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)[:4])
+}
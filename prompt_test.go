@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// promptDataFor는 시스템 로케일을 읽지 않도록 언어를 고정한 템플릿 값을 만든다.
+func promptDataFor(imagePath string, ocrResult OCRResult) PromptData {
+	return newPromptData(Config{OCRLanguages: []string{"ko-KR", "en-US"}}, imagePath, ocrResult)
+}
+
+// writePromptTemplate은 HOME 아래 설정 디렉토리에 사용자 템플릿을 쓴다.
+func writePromptTemplate(t *testing.T, name, text string) {
+	t.Helper()
+	if err := os.MkdirAll(promptDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(promptPath(name), []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewPromptData(t *testing.T) {
+	ocr := OCRResult{Text: "Slack #general", HasText: true}
+	data := promptDataFor("/Users/me/Desktop/Screenshot 2025-01-15 at 12.30.45.png", ocr)
+
+	if data.Filename != "Screenshot 2025-01-15 at 12.30.45.png" || data.Dir != "/Users/me/Desktop" {
+		t.Errorf("Filename/Dir = %q, %q", data.Filename, data.Dir)
+	}
+	if want := time.Date(2025, 1, 15, 12, 30, 45, 0, time.Local); !data.CaptureTime.Equal(want) {
+		t.Errorf("CaptureTime = %v, want %v", data.CaptureTime, want)
+	}
+	if data.Locale != "ko-KR" {
+		t.Errorf("Locale = %q, want ko-KR", data.Locale)
+	}
+	if data.OCRText != "Slack #general" || !strings.Contains(data.OCR, "<<<UNTRUSTED_") {
+		t.Errorf("OCRText = %q, OCR = %q", data.OCRText, data.OCR)
+	}
+}
+
+func TestBuiltinPrompts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	data := promptDataFor("/img.png", OCRResult{})
+
+	if got, want := buildPrompt(data), "이미지 경로: /img.png\n이미지를 분석하고 파일명을 생성해줘."; got != want {
+		t.Errorf("buildPrompt = %q, want %q", got, want)
+	}
+	system := buildSystemPrompt(data)
+	for _, want := range []string{"스크린샷 파일명 생성기", "2-5단어", "한글 또는 영어, 자연스러운 쪽으로 선택"} {
+		if !strings.Contains(system, want) {
+			t.Errorf("buildSystemPrompt should contain %q: %q", want, system)
+		}
+	}
+	if strings.Contains(system, "표기:") {
+		t.Errorf("buildSystemPrompt should not mention casing by default: %q", system)
+	}
+	if got := buildCodexPrompt(data); !strings.HasPrefix(got, system+"\n\n---\n\n") {
+		t.Errorf("buildCodexPrompt should start with the system prompt: %q", got)
+	}
+}
+
+func TestUserPromptTemplates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	data := promptDataFor("/shots/Screenshot 2025-01-15 at 12.30.45.png", OCRResult{Text: "PR #42 review", HasText: true})
+
+	writePromptTemplate(t, promptSystem, "너는 {{.Locale}} 팀의 파일명 생성기야. 용어: JIRA, PR")
+	writePromptTemplate(t, promptClaude, `{{.CaptureTime.Format "2006-01-02"}} {{.Filename}} in {{.Dir}}{{if .HasText}}
+{{.OCR}}{{end}}`)
+	writePromptTemplate(t, promptCodex, "{{.System}} / {{.OCRText | upper}}")
+
+	if got := buildSystemPrompt(data); got != "너는 ko-KR 팀의 파일명 생성기야. 용어: JIRA, PR" {
+		t.Errorf("system = %q", got)
+	}
+	got := buildPrompt(data)
+	if !strings.HasPrefix(got, "2025-01-15 Screenshot 2025-01-15 at 12.30.45.png in /shots\n") || !strings.Contains(got, "PR #42 review") {
+		t.Errorf("claude prompt = %q", got)
+	}
+	if got := buildCodexPrompt(data); got != "너는 ko-KR 팀의 파일명 생성기야. 용어: JIRA, PR / PR #42 REVIEW" {
+		t.Errorf("codex prompt = %q", got)
+	}
+}
+
+func TestUserPromptTemplateErrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	data := promptDataFor("/img.png", OCRResult{})
+	want := buildPrompt(data)
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"parse error", "{{if .HasText}"},
+		{"unknown field", "{{.Nope}}"},
+		{"unknown function", "{{shout .Filename}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writePromptTemplate(t, promptClaude, tt.text)
+			if got := buildPrompt(data); got != want {
+				t.Errorf("broken template should fall back to built-in: %q", got)
+			}
+		})
+	}
+}
+
+func TestPromptVersionTracksTemplates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	before := promptVersion()
+	writePromptTemplate(t, promptSystem, "custom")
+	after := promptVersion()
+	if before == after {
+		t.Error("promptVersion should change when a user template is added")
+	}
+	writePromptTemplate(t, promptSystem, "custom v2")
+	if promptVersion() == after {
+		t.Error("promptVersion should change when a user template is edited")
+	}
+}
+
+func TestRunPromptRender(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(configDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"provider": "claude", "ocr_policy": "never", "ocr_languages": ["ko-KR"]}`
+	if err := os.WriteFile(configPath(), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writePromptTemplate(t, promptClaude, "파일: {{.Filename}}")
+	shot := writeTestPNG(t, t.TempDir(), "Screenshot 2025-01-15 at 12.30.45.png", false)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCLI([]string{"prompt", "render", shot}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"## system", "스크린샷 파일명 생성기", "## prompt\n파일: Screenshot 2025-01-15 at 12.30.45.png"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q: %q", want, out)
+		}
+	}
+
+	stdout.Reset()
+	if code := runCLI([]string{"prompt", "render", filepath.Join(home, "missing.png")}, nil, &stdout, &stderr); code != 1 {
+		t.Errorf("missing file exit code = %d, want 1", code)
+	}
+	if code := runCLI([]string{"prompt"}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("missing subcommand exit code = %d, want 2", code)
+	}
+}
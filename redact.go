@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 가린 민감 정보 종류
+const (
+	RedactSecret = "secret"
+	RedactCard   = "card"
+	RedactRRN    = "rrn"
+	RedactEmail  = "email"
+	RedactPhone  = "phone"
+	RedactCustom = "custom"
+)
+
+const (
+	// 이보다 짧은 토큰은 엔트로피 검사를 하지 않는다
+	minSecretLen = 20
+	// 문자당 Shannon 엔트로피가 이 이상이면 비밀 값으로 본다 (bits)
+	secretEntropyThreshold = 3.5
+)
+
+// Redaction은 프롬프트에 넣기 전에 가린 민감 정보 종류별 개수다. 원래 값은 담지 않는다.
+type Redaction struct {
+	Kind  string
+	Count int
+}
+
+// redactor는 OCR 텍스트 한 덩어리에서 민감 정보를 찾아 치환한다.
+type redactor struct {
+	kind    string
+	pattern *regexp.Regexp
+	// valid가 있으면 통과한 매치만 가린다 (Luhn, 생년월일 등)
+	valid func(match string) bool
+}
+
+var builtinRedactors = []redactor{
+	// 개인키, 잘 알려진 토큰 접두사, password=... 형식
+	{RedactSecret, regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`), nil},
+	{RedactSecret, regexp.MustCompile(`\b(?:sk-[A-Za-z0-9_-]{16,}|gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,}|xox[abprs]-[A-Za-z0-9-]{10,}|AKIA[0-9A-Z]{16}|AIza[0-9A-Za-z_-]{35}|eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})`), nil},
+	{RedactSecret, regexp.MustCompile(`(?i)(?:\b(?:password|passwd|pwd|secret|token|api[_-]?key)|비밀번호|패스워드)\s*[:=]\s*\S+`), nil},
+	{RedactRRN, regexp.MustCompile(`\b\d{6}\s?-\s?[1-8]\d{6}\b`), validRRN},
+	{RedactCard, regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), validLuhn},
+	{RedactEmail, regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), nil},
+	{RedactPhone, regexp.MustCompile(`(?:\+\d{1,3}[ -]?|\(|\b)0?\d{2,3}\)?[ .-]\d{3,4}[ .-]\d{4}\b`), nil},
+	// 접두사가 없는 API 키 등은 길이와 엔트로피로 판단
+	{RedactSecret, regexp.MustCompile(`[A-Za-z0-9+/_=-]{20,}`), isHighEntropy},
+}
+
+// redactPlaceholder는 가린 자리에 넣는 표시다.
+func redactPlaceholder(kind string) string {
+	return "[" + strings.ToUpper(kind) + "]"
+}
+
+// compileRedactors는 내장 탐지기 뒤에 사용자 정의 패턴을 붙인다.
+func compileRedactors(patterns []string) []redactor {
+	redactors := append([]redactor(nil), builtinRedactors...)
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			configLog.Warn("잘못된 redact 패턴", "pattern", p, "err", err)
+			continue
+		}
+		redactors = append(redactors, redactor{kind: RedactCustom, pattern: re})
+	}
+	return redactors
+}
+
+// redactText는 text의 민감 정보를 치환하고 종류별 개수를 counts에 더한다.
+func redactText(text string, redactors []redactor, counts map[string]int) string {
+	for _, r := range redactors {
+		text = r.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if r.valid != nil && !r.valid(match) {
+				return match
+			}
+			counts[r.kind]++
+			return redactPlaceholder(r.kind)
+		})
+	}
+	return text
+}
+
+// redactOCR은 프롬프트에 들어가는 OCR 텍스트와 라인에서 민감 정보를 가린다.
+// 리포트의 개수는 전체 텍스트 기준이다.
+func redactOCR(cfg Config, ocrResult OCRResult) (OCRResult, []Redaction) {
+	if !cfg.RedactEnabled() || !ocrResult.HasText {
+		return ocrResult, nil
+	}
+	redactors := compileRedactors(cfg.RedactPatterns)
+
+	counts := map[string]int{}
+	redacted := ocrResult
+	redacted.Text = redactText(ocrResult.Text, redactors, counts)
+	if ocrResult.Lines != nil {
+		redacted.Lines = make([]OCRLine, len(ocrResult.Lines))
+		for i, line := range ocrResult.Lines {
+			line.Text = redactText(line.Text, redactors, map[string]int{})
+			redacted.Lines[i] = line
+		}
+	}
+
+	var report []Redaction
+	for kind, n := range counts {
+		report = append(report, Redaction{Kind: kind, Count: n})
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Kind < report[j].Kind })
+	return redacted, report
+}
+
+// redactionSummary는 "card 1, email 2" 형식의 로그용 요약이다.
+func redactionSummary(report []Redaction) string {
+	parts := make([]string, len(report))
+	for i, r := range report {
+		parts[i] = fmt.Sprintf("%s %d", r.Kind, r.Count)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// validLuhn은 구분자를 뺀 숫자열이 Luhn 검사를 통과하는지 확인한다.
+func validLuhn(s string) bool {
+	var digits []int
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			digits = append(digits, int(c-'0'))
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := range digits {
+		d := digits[len(digits)-1-i]
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// validRRN은 주민등록번호 앞자리가 실제 생년월일인지 확인한다.
+// 2020년 10월 이후 발급 번호는 검증번호 규칙이 없어 체크섬은 보지 않는다.
+func validRRN(s string) bool {
+	var digits []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			digits = append(digits, s[i])
+		}
+	}
+	if len(digits) != 13 {
+		return false
+	}
+	century := map[byte]string{'1': "19", '2': "19", '3': "20", '4': "20", '5': "19", '6': "19", '7': "20", '8': "20"}[digits[6]]
+	_, err := time.Parse("20060102", century+string(digits[:6]))
+	return err == nil
+}
+
+// isHighEntropy는 긴 토큰이 무작위 문자열(API 키 등)처럼 보이는지 판단한다.
+// 대문자/소문자/숫자가 모두 섞여 있거나 긴 16진수 문자열이어야 하고,
+// 문자당 엔트로피가 임계값 이상이어야 한다. "slack-chat-2026" 같은 단어 나열은 제외된다.
+func isHighEntropy(s string) bool {
+	if len(s) < minSecretLen {
+		return false
+	}
+	var upper, lower, digit, nonHex bool
+	for _, c := range s {
+		upper = upper || unicode.IsUpper(c)
+		lower = lower || unicode.IsLower(c)
+		digit = digit || unicode.IsDigit(c)
+		nonHex = nonHex || !strings.ContainsRune("0123456789abcdefABCDEF", c)
+	}
+	mixed := upper && lower && digit
+	hex := !nonHex && len(s) >= 32 && digit && (upper || lower)
+	if !mixed && !hex {
+		return false
+	}
+	return shannonEntropy(s) >= secretEntropyThreshold
+}
+
+// shannonEntropy는 문자 빈도로 계산한 문자당 엔트로피(bits)다.
+func shannonEntropy(s string) float64 {
+	freq := map[rune]int{}
+	n := 0
+	for _, c := range s {
+		freq[c]++
+		n++
+	}
+	var h float64
+	for _, count := range freq {
+		p := float64(count) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -13,61 +17,425 @@ import (
 var datePattern = regexp.MustCompile(`(\d{4}-\d{2}-\d{2})`)
 
 type RenameResult struct {
+	// 처리 작업 번호 (로그를 작업별로 묶는 데 쓴다)
+	JobID        uint64
 	OriginalPath string
 	NewPath      string
 	Success      bool
 	Error        error
+	// 템플릿을 적용하기 전의 제안 이름과 프롬프트에 넣은 (가린) OCR 텍스트 (수동 수정 학습에 쓴다)
+	SuggestedName string
+	PromptOCRText string
+	// 적용하지 않은 다른 후보 (name_candidates, 좋은 순서)
+	Alternatives []string
+	// 승인 대기열 번호 (confirm_rename, 0이면 바로 리네이밍함)
+	PendingID int
+
+	// OCR 수행 여부와 그렇게 결정한 이유 (ocr_policy)
+	OCRRan    bool
+	OCRReason string
+	// 캐시에서 가져온 결과인지 여부
+	OCRCached  bool
+	NameCached bool
+	// 거의 같은 최근 스크린샷 경로 (duplicate_action)
+	DuplicateOf string
+	// 중복으로 삭제됐는지 여부
+	Deleted bool
+	// 리네이밍을 되돌려 원래 이름으로 돌아갔는지 여부
+	Undone bool
+	// 감시자가 파일을 감지한 시점의 맨 앞 앱 (privacy 규칙, 알 수 없으면 빈 문자열)
+	CaptureApp string
+	// 걸린 privacy 규칙 이름과 이유 (local only, 프로바이더 호출 안 함)
+	LocalOnly       string
+	LocalOnlyReason string
+	// privacy 규칙에 따라 파일을 그대로 두었는지 여부
+	Skipped bool
+	// 프로바이더 출력이 거부되어 기본 이름을 썼다면 그 이유
+	NameRejected string
+	// 프롬프트에 넣기 전에 가린 민감 정보 종류별 개수 (redact)
+	Redactions []Redaction
+	// AI 분석에 보낸 축소 사본 경로 (처리 후 삭제됨, 원본을 보냈으면 빈 문자열)
+	PreprocessedPath string
+	// 단계별 소요 시간
+	OCRDuration    time.Duration
+	NamingDuration time.Duration
 }
 
+// ProcessScreenshot은 스크린샷 하나를 처리한다. 맨 앞 앱을 알 수 없으므로 (CLI)
+// 앱 조건의 privacy 규칙은 적용되지 않는다.
 func ProcessScreenshot(cfg Config, screenshotPath string) RenameResult {
-	result := RenameResult{OriginalPath: screenshotPath}
+	return processCapture(cfg, screenshotPath, "", nil)
+}
+
+// processCapture는 감지 시점의 맨 앞 앱 app과 함께 스크린샷을 처리한다.
+// ignore는 감시자가 앱이 옮긴 파일을 다시 처리하지 않도록 옮길 경로를 알린다 (CLI는 nil).
+func processCapture(cfg Config, screenshotPath, app string, ignore func(path string)) RenameResult {
+	result := RenameResult{OriginalPath: screenshotPath, JobID: nextJobID(), CaptureApp: app}
+	log := result.log()
+
+	// 0. 최근 스크린샷과 거의 같은 이미지인지 확인
+	var candidates []string
+	phash, checked, prev := findDuplicate(cfg, screenshotPath)
+	if prev != nil {
+		result.DuplicateOf = prev.path
+		log.Info("중복 스크린샷", "duplicate_of", prev.path, "action", cfg.DuplicateAction)
+		switch cfg.DuplicateAction {
+		case DuplicateMove:
+			return moveDuplicate(cfg, screenshotPath, result, ignore)
+		case DuplicateDelete:
+			// 삭제를 거절하면 일반 스크린샷처럼 이름을 붙인다
+			if confirmDuplicateDelete(screenshotPath, prev.path) {
+				return deleteDuplicate(screenshotPath, result)
+			}
+			log.Info("중복 스크린샷 유지")
+		default:
+			candidates = []string{recentShots.nextName(prev)}
+		}
+	}
+
+	// 1~2. OCR + AI CLI로 파일명 후보 생성
+	if len(candidates) == 0 {
+		var err error
+		candidates, err = suggestNames(cfg, screenshotPath, &result)
+		if errors.Is(err, errPrivacySkip) {
+			result.Skipped = true
+			log.Info("그대로 둠", "privacy_rule", result.LocalOnly)
+			return result
+		}
+		if err != nil {
+			result.Error = fmt.Errorf("naming failed: %w", err)
+			log.Error("네이밍 실패", "err", err)
+			return result
+		}
+	}
+	names := finishNames(cfg, candidates)
+	suggestedName := names[0]
+	result.SuggestedName, result.Alternatives = suggestedName, names[1:]
+	log.Info("제안된 이름", "name", suggestedName, "alternatives", result.Alternatives)
+	if cfg.ConfirmRename {
+		return queueRename(screenshotPath, result)
+	}
+
+	result = applyName(cfg, screenshotPath, suggestedName, result)
+	if result.Success && checked && result.DuplicateOf == "" {
+		recentShots.add(phash, result.NewPath, suggestedName)
+	}
+	return result
+}
+
+// log는 이 작업의 로그에 작업 번호와 원본 경로를 붙인다.
+func (r RenameResult) log() *slog.Logger {
+	return renamerLog.With("job", r.JobID, "path", r.OriginalPath)
+}
+
+// finishNames는 후보에 용어집, ASCII 변환, 표기 방식을 적용하고 겹치는 것을 뺀다.
+// 모든 후보가 비면 기본 이름 하나를 반환한다.
+func finishNames(cfg Config, candidates []string) []string {
+	glossary := loadGlossary(cfg)
+	var names []string
+	for _, name := range candidates {
+		name = SanitizeFilename(glossary.Normalize(name), cfg.MaxFileNameLen)
+		if cfg.ASCIIFilenames {
+			name = SanitizeFilename(toASCII(name), cfg.MaxFileNameLen)
+		}
+		name = applyNameStyle(name, cfg.NameCase, cfg.NameMaxWords, glossary)
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return []string{fallbackName}
+	}
+	return names
+}
+
+// suggestNames는 OCR(정책에 따라 생략)과 프로바이더 호출로 파일명 후보를 좋은 순서로 제안한다.
+// 같은 이미지를 다시 처리하면 내용 해시로 이전 결과를 재사용한다.
+func suggestNames(cfg Config, screenshotPath string, result *RenameResult) ([]string, error) {
+	log := result.log()
+	cache := openCache(cfg)
+	imageHash, err := hashFile(screenshotPath)
+	if err != nil {
+		cache = nil
+	}
+
+	// 1. OCR 수행 (정책에 따라 생략, privacy 규칙 판단에 필요하면 수행)
+	ocrResult, ocrErr := extractText(cfg, screenshotPath, cache, imageHash, result)
 
-	// 1. OCR 수행
-	fmt.Printf("[Renamer] OCR 시작: %s\n", filepath.Base(screenshotPath))
-	ocrResult := RunOCR(cfg, screenshotPath)
+	// privacy 규칙에 걸리면 프로바이더에 보내지 않는다 (OCR이 실패해 판단할 수 없어도)
+	if rule, why := matchPrivacyRule(cfg, ocrResult, ocrErr, result.CaptureApp); rule != nil {
+		result.LocalOnly, result.LocalOnlyReason = rule.Name, why
+		log.Info("local only - 프로바이더 호출 안 함", "privacy_rule", rule.Name, "reason", why)
+		if rule.Action == PrivacySkip {
+			return nil, errPrivacySkip
+		}
+		return []string{rule.localName(cfg.MaxFileNameLen)}, nil
+	}
+
+	// 프롬프트에 넣기 전에 민감 정보 가림 (캐시에는 원본 OCR 결과를 보관)
+	ocrResult, result.Redactions = redactOCR(cfg, ocrResult)
+	if len(result.Redactions) > 0 {
+		log.Info("민감 정보 가림", "redactions", redactionSummary(result.Redactions))
+	}
 	if ocrResult.HasText {
-		fmt.Printf("[Renamer] OCR 텍스트 추출됨 (%d자)\n", len([]rune(ocrResult.Text)))
-	} else {
-		fmt.Println("[Renamer] OCR 텍스트 없음 - 이미지 분석으로 진행")
+		result.PromptOCRText = truncate(ocrResult.Prominent(), maxPromptOCRRunes)
 	}
 
 	// 2. AI CLI로 파일명 생성
-	fmt.Printf("[Renamer] %s CLI 호출 중...\n", cfg.Provider)
-	suggestedName, err := GenerateName(cfg, screenshotPath, ocrResult)
+	var names []string
+	nameKey := nameCacheKey(cfg, imageHash, ocrResult)
+	if cache.Get(nameKey, &names) && len(names) > 0 {
+		result.NameCached = true
+		log.Debug("이름 캐시 적중")
+		return names, nil
+	}
+
+	// AI에는 축소/재압축한 사본을 보내고, 리네이밍은 원본에 적용
+	aiImage, cleanup, err := preprocessImage(cfg, screenshotPath)
 	if err != nil {
-		result.Error = fmt.Errorf("naming failed: %w", err)
-		fmt.Printf("[Renamer] 네이밍 실패: %v\n", err)
-		return result
+		log.Warn("이미지 전처리 실패 - 원본 사용", "err", err)
+	}
+	defer cleanup()
+	if aiImage != screenshotPath {
+		result.PreprocessedPath = aiImage
+		log.Debug("AI 분석용 이미지", "size", fileSizeSummary(screenshotPath, aiImage))
+	}
+
+	log.Info("CLI 호출 중", "provider", cfg.Provider)
+	start := time.Now()
+	names, err = GenerateNames(cfg, aiImage, newPromptData(cfg, screenshotPath, ocrResult))
+	result.NamingDuration = time.Since(start)
+	log.Debug("CLI 응답", "provider", cfg.Provider, "duration", result.NamingDuration.Round(time.Millisecond))
+	if errors.Is(err, errInvalidOutput) {
+		// 지시를 따라간 출력일 수 있으므로 캐시하지 않고 기본 이름을 쓴다
+		result.NameRejected = err.Error()
+		log.Warn("출력 거부 - 기본 이름 사용", "err", err)
+		return []string{fallbackName}, nil
 	}
-	fmt.Printf("[Renamer] 제안된 이름: %s\n", suggestedName)
+	if err != nil {
+		return nil, err
+	}
+	cache.Put(nameKey, names)
+	return names, nil
+}
+
+// extractText는 OCR 정책과 privacy 규칙에 따라 OCR을 수행하거나 생략한다.
+// 같은 이미지면 캐시된 OCR 결과를 쓰고, 실패한 OCR은 캐시하지 않는다.
+func extractText(cfg Config, screenshotPath string, cache *Cache, imageHash string, result *RenameResult) (OCRResult, error) {
+	log := result.log()
+	var ocrResult OCRResult
+	var err error
+	result.OCRRan, result.OCRReason = decideOCR(cfg, screenshotPath)
+	if !result.OCRRan && privacyNeedsOCR(cfg) {
+		result.OCRRan, result.OCRReason = true, "privacy rules need OCR text"
+	}
+	if result.OCRRan && cache.Get(ocrCacheKey(cfg, imageHash), &ocrResult) {
+		result.OCRCached = true
+		log.Debug("OCR 캐시 적중")
+	} else if result.OCRRan {
+		log.Info("OCR 시작", "reason", result.OCRReason)
+		start := time.Now()
+		ocrResult, err = RunOCR(cfg, screenshotPath)
+		result.OCRDuration = time.Since(start)
+		if err == nil {
+			cache.Put(ocrCacheKey(cfg, imageHash), ocrResult)
+		}
+		if ocrResult.HasText {
+			log.Info("OCR 텍스트 추출됨", "chars", len([]rune(ocrResult.Text)), "duration", result.OCRDuration.Round(time.Millisecond))
+		} else {
+			log.Info("OCR 텍스트 없음 - 이미지 분석으로 진행", "duration", result.OCRDuration.Round(time.Millisecond))
+		}
+	} else {
+		log.Debug("OCR 생략", "reason", result.OCRReason)
+	}
+	return ocrResult, err
+}
+
+// applyName은 제안된 이름으로 최종 파일명을 만들고 리네이밍한다.
+func applyName(cfg Config, screenshotPath, suggestedName string, result RenameResult) RenameResult {
+	// 4. 중복 처리 후 리네이밍 (destination이 있으면 그 디렉토리로 이동)
+	return moveTo(screenshotPath, targetPath(cfg, screenshotPath, suggestedName), result, nil)
+}
 
-	// 3. 날짜 추출 + 최종 파일명 조합
-	date := extractDate(filepath.Base(screenshotPath))
+// targetPath는 원본 스크린샷 경로와 제안된 이름으로 최종 경로를 만든다.
+func targetPath(cfg Config, screenshotPath, suggestedName string) string {
+	// 3. 촬영 시각 추출 + 최종 파일명 조합
+	taken := captureTime(filepath.Base(screenshotPath), cfg.ScreenshotPatterns())
 	ext := filepath.Ext(screenshotPath)
-	newName := fmt.Sprintf("%s_%s%s", date, suggestedName, ext)
+	newName := encodeFilename(formatName(cfg.NameTemplate, taken, suggestedName, screenshotPath), cfg) + ext
 
-	// 4. 중복 처리 후 리네이밍
 	dir := filepath.Dir(screenshotPath)
-	newPath := resolveConflict(filepath.Join(dir, newName))
+	if cfg.Destination != "" {
+		dir = expandHome(cfg.Destination)
+	}
+	return filepath.Join(dir, newName)
+}
+
+// SwitchName은 리네이밍한 파일을 다른 후보 이름으로 바꾼다.
+// 성공하면 이전 이름이 후보 목록으로 돌아간다. ignore가 있으면 옮기기 전에 최종 경로를 알린다
+// (감시자가 앱의 리네이밍을 사용자 수정으로 기록하지 않도록).
+func SwitchName(cfg Config, result RenameResult, name string, ignore func(path string)) RenameResult {
+	i := slices.Index(result.Alternatives, name)
+	if !result.Success || result.Deleted || i < 0 {
+		result.Error = fmt.Errorf("no such candidate: %s", name)
+		return result
+	}
+	switched := moveTo(result.NewPath, targetPath(cfg, result.OriginalPath, name), result, ignore)
+	if switched.Error != nil {
+		return switched
+	}
+	switched.Alternatives = slices.Clone(result.Alternatives)
+	switched.Alternatives[i] = result.SuggestedName
+	switched.SuggestedName = name
+	return switched
+}
 
-	if err := os.Rename(screenshotPath, newPath); err != nil {
+// RenameTo는 리네이밍한 파일을 사용자가 입력한 이름으로 바꾼다.
+// 입력한 이름도 제안 이름과 같은 규칙(용어집, 표기)으로 정리한다. ignore는 SwitchName과 같다.
+func RenameTo(cfg Config, result RenameResult, name string, ignore func(path string)) RenameResult {
+	if !result.Success || result.Deleted {
+		result.Error = fmt.Errorf("nothing to rename: %s", result.OriginalPath)
+		return result
+	}
+	name = finishNames(cfg, []string{name})[0]
+	renamed := moveTo(result.NewPath, targetPath(cfg, result.OriginalPath, name), result, ignore)
+	if renamed.Error != nil {
+		return renamed
+	}
+	renamed.SuggestedName = name
+	return renamed
+}
+
+// UndoRename은 리네이밍한 파일을 원래 이름으로 되돌린다.
+// 그사이 원래 이름으로 다른 파일이 생겼으면 덮어쓰지 않고 실패한다.
+func UndoRename(result RenameResult) RenameResult {
+	if !result.Success || result.Deleted {
+		result.Error = fmt.Errorf("nothing to undo: %s", result.OriginalPath)
+		return result
+	}
+	if _, err := os.Stat(result.OriginalPath); err == nil {
+		result.Error = fmt.Errorf("undo failed: %s already exists", result.OriginalPath)
+		return result
+	}
+	if err := moveFile(result.NewPath, result.OriginalPath); err != nil {
+		result.Error = fmt.Errorf("undo failed: %w", err)
+		return result
+	}
+	result.log().Info("되돌림", "from", result.NewPath)
+	return RenameResult{JobID: result.JobID, OriginalPath: result.OriginalPath, NewPath: result.OriginalPath, Undone: true}
+}
+
+// moveTo는 대상 디렉토리를 만들고 이름이 겹치지 않게 파일을 옮긴다.
+// ignore가 있으면 옮기기 전에 겹치지 않게 정한 최종 경로로 호출한다.
+func moveTo(screenshotPath, target string, result RenameResult, ignore func(path string)) RenameResult {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		result.Error = fmt.Errorf("create destination failed: %w", err)
+		result.log().Error("대상 디렉토리 생성 실패", "err", err)
+		return result
+	}
+	newPath := resolveConflict(target)
+	if ignore != nil {
+		ignore(newPath)
+	}
+
+	if err := moveFile(screenshotPath, newPath); err != nil {
 		result.Error = fmt.Errorf("rename failed: %w", err)
-		fmt.Printf("[Renamer] 리네이밍 실패: %v\n", err)
+		result.log().Error("리네이밍 실패", "err", err)
 		return result
 	}
 
 	result.NewPath = newPath
 	result.Success = true
-	fmt.Printf("[Renamer] 완료: %s → %s\n", filepath.Base(screenshotPath), filepath.Base(newPath))
+	result.log().Info("완료", "from", screenshotPath, "to", newPath)
+	return result
+}
+
+// moveFile은 파일을 옮긴다. destination이 다른 볼륨에 있어 rename할 수 없으면
+// 복사한 뒤 원본을 지운다 (권한과 수정 시각 유지).
+func moveFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := copyFile(src, dst); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	os.Chmod(dst, info.Mode().Perm())
+	os.Chtimes(dst, info.ModTime(), info.ModTime())
+	return os.Remove(src)
+}
+
+// moveDuplicate는 중복 스크린샷을 원래 이름 그대로 중복 폴더로 옮긴다.
+// 중복 폴더는 다른 디렉토리라 이동 짝짓기가 되지 않으므로 ignore로 옮길 경로를 알린다.
+func moveDuplicate(cfg Config, screenshotPath string, result RenameResult, ignore func(path string)) RenameResult {
+	target := filepath.Join(duplicatesDir(cfg, screenshotPath), filepath.Base(screenshotPath))
+	return moveTo(screenshotPath, target, result, ignore)
+}
+
+// deleteDuplicate는 사용자가 삭제를 확인한 중복 스크린샷을 지운다.
+func deleteDuplicate(screenshotPath string, result RenameResult) RenameResult {
+	if err := os.Remove(screenshotPath); err != nil {
+		result.Error = fmt.Errorf("delete duplicate failed: %w", err)
+		return result
+	}
+	result.Deleted = true
+	result.Success = true
+	result.log().Info("중복 스크린샷 삭제")
 	return result
 }
 
-func extractDate(filename string) string {
-	match := datePattern.FindString(filename)
-	if match != "" {
-		return match
+// findDuplicate는 duplicate_action이 켜져 있으면 perceptual hash를 계산하고
+// 거의 같은 최근 스크린샷을 찾는다. checked는 해시 계산에 성공했는지 여부다.
+func findDuplicate(cfg Config, screenshotPath string) (phash uint64, checked bool, prev *recentShot) {
+	if cfg.DuplicateAction == "" || cfg.DuplicateAction == DuplicateOff {
+		return 0, false, nil
+	}
+	phash, err := perceptualHash(screenshotPath)
+	if err != nil {
+		renamerLog.Warn("perceptual hash 실패", "path", screenshotPath, "err", err)
+		return 0, false, nil
+	}
+
+	threshold := cfg.DuplicateThreshold
+	if threshold <= 0 {
+		threshold = defaultDuplicateThreshold
+	}
+	prev, _ = recentShots.similar(phash, threshold)
+	return phash, true, prev
+}
+
+// formatName은 파일명 템플릿의 {date}, {time}, {name}, {original}을 치환한다.
+func formatName(template string, taken time.Time, name, original string) string {
+	if template == "" {
+		template = defaultNameTemplate
+	}
+	base := strings.TrimSuffix(filepath.Base(original), filepath.Ext(original))
+	r := strings.NewReplacer(
+		"{date}", taken.Format("2006-01-02"),
+		"{time}", taken.Format("15-04-05"),
+		"{name}", name,
+		"{original}", base,
+	)
+	// 템플릿에 경로 구분자가 있어도 디렉토리를 만들지 않도록 치환
+	return strings.ReplaceAll(r.Replace(template), string(filepath.Separator), "-")
+}
+
+// captureTime은 파일명 패턴에서 촬영 시각을 추출하고, 실패하면 파일명의 날짜, 그것도 없으면 현재 시각을 사용한다.
+func captureTime(filename string, patterns []*regexp.Regexp) time.Time {
+	if t, ok := screenshotTime(filename, patterns); ok {
+		return t
+	}
+	if date := datePattern.FindString(filename); date != "" {
+		if t, err := time.ParseInLocation("2006-01-02", date, time.Local); err == nil {
+			return t
+		}
 	}
-	return time.Now().Format("2006-01-02")
+	return time.Now()
 }
 
 func resolveConflict(path string) string {
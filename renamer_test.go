@@ -1,13 +1,17 @@
 package main
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 )
 
-func TestExtractDate(t *testing.T) {
+func TestCaptureTimeDate(t *testing.T) {
+	// 파일명 패턴 없이 파일명의 날짜만으로 촬영일을 정한다
 	tests := []struct {
 		name     string
 		filename string
@@ -29,26 +33,18 @@ func TestExtractDate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := extractDate(tt.filename)
+			got := captureTime(tt.filename, nil).Format("2006-01-02")
 			if got != tt.want {
-				t.Errorf("extractDate(%q) = %q, want %q", tt.filename, got, tt.want)
+				t.Errorf("captureTime(%q) = %q, want %q", tt.filename, got, tt.want)
 			}
 		})
 	}
 
-	t.Run("no date returns today", func(t *testing.T) {
-		got := extractDate("random-file.png")
-		// 형식이 YYYY-MM-DD인지만 확인
-		if len(got) != 10 || got[4] != '-' || got[7] != '-' {
-			t.Errorf("extractDate with no date should return today's date format, got %q", got)
-		}
-	})
-
 	t.Run("partial date not matched", func(t *testing.T) {
-		got := extractDate("file-2025-1-5.png") // 월/일이 한 자리
-		// 패턴이 \d{4}-\d{2}-\d{2}이므로 매칭 안 됨 → 오늘 날짜
-		if len(got) != 10 || got[4] != '-' || got[7] != '-' {
-			t.Errorf("partial date should fallback to today, got %q", got)
+		got := captureTime("file-2025-1-5.png", nil) // 월/일이 한 자리
+		// 패턴이 \d{4}-\d{2}-\d{2}이므로 매칭 안 됨 → 현재 시각
+		if time.Since(got) > time.Minute {
+			t.Errorf("partial date should fallback to now, got %v", got)
 		}
 	})
 }
@@ -138,3 +134,416 @@ func TestResolveConflict(t *testing.T) {
 		}
 	})
 }
+
+func TestFormatName(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"default template", "", "2025-01-15_슬랙-대화"},
+		{"name only", "{name}", "슬랙-대화"},
+		{"name first", "{name}_{date}", "슬랙-대화_2025-01-15"},
+		{"with time", "{date}_{time}_{name}", "2025-01-15_12-30-45_슬랙-대화"},
+		{"original filename", "{date}_{original}_{name}", "2025-01-15_Screenshot 2025-01-15 at 12.30.45_슬랙-대화"},
+		{"path separator replaced", "{date}/{name}", "2025-01-15-슬랙-대화"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taken := time.Date(2025, 1, 15, 12, 30, 45, 0, time.Local)
+			got := formatName(tt.template, taken, "슬랙-대화", "/shots/Screenshot 2025-01-15 at 12.30.45.png")
+			if got != tt.want {
+				t.Errorf("formatName(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaptureTime(t *testing.T) {
+	patterns := compilePatterns([]string{"macos-en", "kde-spectacle"}, nil)
+
+	tests := []struct {
+		name     string
+		filename string
+		want     time.Time
+	}{
+		{"preset timestamp", "Screenshot 2025-01-15 at 12.30.45.png", time.Date(2025, 1, 15, 12, 30, 45, 0, time.Local)},
+		{"compact preset timestamp", "Screenshot_20250115_123045.png", time.Date(2025, 1, 15, 12, 30, 45, 0, time.Local)},
+		{"date in other filename", "backup-2024-12-31.png", time.Date(2024, 12, 31, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := captureTime(tt.filename, patterns); !got.Equal(tt.want) {
+				t.Errorf("captureTime(%q) = %v, want %v", tt.filename, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no date returns now", func(t *testing.T) {
+		got := captureTime("random-file.png", patterns)
+		if time.Since(got) > time.Minute {
+			t.Errorf("captureTime without date = %v, want now", got)
+		}
+	})
+}
+
+func TestProcessScreenshot(t *testing.T) {
+	// 캐시가 실제 설정 디렉토리에 쓰이지 않도록
+	t.Setenv("HOME", t.TempDir())
+
+	t.Run("renames with provider suggestion", func(t *testing.T) {
+		dir := t.TempDir()
+		shot := writeTestPNG(t, dir, "Screenshot 2025-01-15 at 12.30.45.png", true)
+		claude, _ := fakeCLI(t, "슬랙 대화\n")
+
+		cfg := Config{Provider: ProviderClaude, ClaudePath: claude, MaxFileNameLen: 80, OCRPolicy: OCRPolicyNever}
+		result := ProcessScreenshot(cfg, shot)
+		if !result.Success {
+			t.Fatalf("ProcessScreenshot failed: %v", result.Error)
+		}
+		want := filepath.Join(dir, "2025-01-15_슬랙-대화.png")
+		if result.NewPath != want {
+			t.Errorf("NewPath = %q, want %q", result.NewPath, want)
+		}
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("renamed file should exist: %v", err)
+		}
+		if result.OCRRan || result.OCRReason != "policy never" {
+			t.Errorf("OCR decision = %v (%s), want skipped by policy", result.OCRRan, result.OCRReason)
+		}
+	})
+
+	t.Run("destination directory", func(t *testing.T) {
+		dir := t.TempDir()
+		dest := filepath.Join(t.TempDir(), "archive", "captures")
+		shot := writeTestPNG(t, dir, "Screenshot 2025-01-15 at 12.30.45.png", false)
+		claude, _ := fakeCLI(t, "github-pr")
+
+		cfg := Config{
+			Provider:       ProviderClaude,
+			ClaudePath:     claude,
+			MaxFileNameLen: 80,
+			OCRPolicy:      OCRPolicyNever,
+			NameTemplate:   "{name}_{date}",
+			Destination:    dest,
+		}
+		result := ProcessScreenshot(cfg, shot)
+		if want := filepath.Join(dest, "github-pr_2025-01-15.png"); result.NewPath != want {
+			t.Errorf("NewPath = %q, want %q (err %v)", result.NewPath, want, result.Error)
+		}
+	})
+
+	t.Run("naming failure keeps original", func(t *testing.T) {
+		dir := t.TempDir()
+		shot := writeTestPNG(t, dir, "Screenshot 2025-01-15 at 12.30.45.png", false)
+
+		cfg := Config{Provider: ProviderClaude, ClaudePath: filepath.Join(dir, "missing"), OCRPolicy: OCRPolicyNever}
+		result := ProcessScreenshot(cfg, shot)
+		if result.Success || result.Error == nil {
+			t.Error("ProcessScreenshot should fail when provider fails")
+		}
+		if _, err := os.Stat(shot); err != nil {
+			t.Errorf("original should be untouched: %v", err)
+		}
+	})
+}
+
+func TestProcessScreenshotCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	claude, argsFile := fakeCLI(t, "슬랙-대화")
+	cfg := Config{Provider: ProviderClaude, ClaudePath: claude, MaxFileNameLen: 80, OCRPolicy: OCRPolicyNever}
+
+	dir := t.TempDir()
+	first := writeTestPNG(t, dir, "Screenshot 2025-01-15 at 12.30.45.png", true)
+	if result := ProcessScreenshot(cfg, first); !result.Success || result.NameCached {
+		t.Fatalf("first run = %+v, want fresh success", result)
+	}
+	os.Remove(argsFile)
+
+	// 같은 내용의 이미지는 프로바이더를 다시 호출하지 않는다
+	second := writeTestPNG(t, dir, "Screenshot 2025-01-16 at 09.00.00.png", true)
+	result := ProcessScreenshot(cfg, second)
+	if !result.Success || !result.NameCached {
+		t.Fatalf("second run = %+v, want cached success", result)
+	}
+	if want := filepath.Join(dir, "2025-01-16_슬랙-대화.png"); result.NewPath != want {
+		t.Errorf("NewPath = %q, want %q", result.NewPath, want)
+	}
+	if _, err := os.Stat(argsFile); err == nil {
+		t.Error("provider should not be called on cache hit")
+	}
+
+	// 프로바이더가 바뀌면 캐시 키도 달라진다
+	codex, _ := fakeCLI(t, "codex-name")
+	cfg.Provider, cfg.CodexPath = ProviderCodex, codex
+	third := writeTestPNG(t, dir, "Screenshot 2025-01-17 at 09.00.00.png", true)
+	if result := ProcessScreenshot(cfg, third); result.NameCached {
+		t.Error("different provider should miss the cache")
+	}
+
+	// 캐시를 끄면 저장/조회하지 않는다
+	off := false
+	cfg.Provider, cfg.Cache = ProviderClaude, &off
+	fourth := writeTestPNG(t, dir, "Screenshot 2025-01-18 at 09.00.00.png", true)
+	if result := ProcessScreenshot(cfg, fourth); result.NameCached {
+		t.Error("disabled cache should not be used")
+	}
+}
+
+func TestProcessScreenshotDuplicates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	saved := recentShots
+	t.Cleanup(func() { recentShots = saved })
+
+	setup := func(t *testing.T, action DuplicateAction) (Config, string) {
+		recentShots = &shotHistory{}
+		claude, _ := fakeCLI(t, "slack-chat")
+		off := false
+		cfg := Config{
+			Provider:        ProviderClaude,
+			ClaudePath:      claude,
+			MaxFileNameLen:  80,
+			OCRPolicy:       OCRPolicyNever,
+			Cache:           &off,
+			DuplicateAction: action,
+		}
+		dir := t.TempDir()
+		first := filepath.Join(dir, "Screenshot 2025-01-15 at 12.30.45.png")
+		writeGradientPNG(t, first, 400, 300, 10)
+		if result := ProcessScreenshot(cfg, first); !result.Success {
+			t.Fatalf("first screenshot failed: %v", result.Error)
+		}
+		second := filepath.Join(dir, "Screenshot 2025-01-15 at 12.30.50.png")
+		writeGradientPNG(t, second, 400, 300, 10)
+		return cfg, second
+	}
+
+	t.Run("sequence", func(t *testing.T) {
+		cfg, second := setup(t, DuplicateSequence)
+		cfg.ClaudePath = filepath.Join(t.TempDir(), "must-not-be-called")
+
+		result := ProcessScreenshot(cfg, second)
+		if !result.Success || result.DuplicateOf == "" {
+			t.Fatalf("result = %+v, want duplicate success", result)
+		}
+		if want := filepath.Join(filepath.Dir(second), "2025-01-15_slack-chat-2.png"); result.NewPath != want {
+			t.Errorf("NewPath = %q, want %q", result.NewPath, want)
+		}
+	})
+
+	t.Run("move", func(t *testing.T) {
+		cfg, second := setup(t, DuplicateMove)
+
+		// 중복 폴더는 다른 디렉토리라 감시자에게 옮길 경로를 먼저 알린다
+		var ignored []string
+		result := processCapture(cfg, second, "", func(path string) { ignored = append(ignored, path) })
+		want := filepath.Join(filepath.Dir(second), "duplicates", filepath.Base(second))
+		if !result.Success || result.NewPath != want {
+			t.Errorf("NewPath = %q, want %q (err %v)", result.NewPath, want, result.Error)
+		}
+		if len(ignored) != 1 || ignored[0] != want {
+			t.Errorf("ignored = %q, want [%q]", ignored, want)
+		}
+	})
+
+	t.Run("delete confirmed", func(t *testing.T) {
+		cfg, second := setup(t, DuplicateDelete)
+		confirm := confirmDuplicateDelete
+		t.Cleanup(func() { confirmDuplicateDelete = confirm })
+		confirmDuplicateDelete = func(path, original string) bool { return true }
+
+		result := ProcessScreenshot(cfg, second)
+		if !result.Deleted {
+			t.Errorf("result = %+v, want deleted", result)
+		}
+		if _, err := os.Stat(second); !os.IsNotExist(err) {
+			t.Error("duplicate should be deleted")
+		}
+	})
+
+	t.Run("delete declined names normally", func(t *testing.T) {
+		cfg, second := setup(t, DuplicateDelete)
+		confirm := confirmDuplicateDelete
+		t.Cleanup(func() { confirmDuplicateDelete = confirm })
+		confirmDuplicateDelete = func(path, original string) bool { return false }
+
+		result := ProcessScreenshot(cfg, second)
+		if result.Deleted || !result.Success {
+			t.Errorf("result = %+v, want renamed and kept", result)
+		}
+	})
+
+	t.Run("off", func(t *testing.T) {
+		cfg, second := setup(t, DuplicateOff)
+		if result := ProcessScreenshot(cfg, second); result.DuplicateOf != "" {
+			t.Error("duplicate detection should be disabled")
+		}
+	})
+}
+
+func TestProcessScreenshotCandidates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	shot := writeTestPNG(t, dir, "Screenshot 2025-01-15 at 12.30.45.png", true)
+	claude, argsFile := fakeCLI(t, "1. Slack-Chat\n2. slack-배포-논의\n3. slack-chat")
+	cfg := Config{Provider: ProviderClaude, ClaudePath: claude, MaxFileNameLen: 80, OCRPolicy: OCRPolicyNever, NameCandidates: 3, NameCase: NameCaseKebab}
+
+	result := ProcessScreenshot(cfg, shot)
+	if !result.Success {
+		t.Fatalf("ProcessScreenshot failed: %v", result.Error)
+	}
+	if want := filepath.Join(dir, "2025-01-15_slack-chat.png"); result.NewPath != want {
+		t.Errorf("NewPath = %q, want %q", result.NewPath, want)
+	}
+	// 표기 방식을 적용한 뒤 겹치는 후보는 하나로 합친다
+	if len(result.Alternatives) != 1 || result.Alternatives[0] != "slack-배포-논의" {
+		t.Errorf("Alternatives = %q, want [slack-배포-논의]", result.Alternatives)
+	}
+	if args := strings.Join(readArgs(t, argsFile), "\n"); !strings.Contains(args, "파일명 후보 3개") {
+		t.Errorf("system prompt should ask for 3 candidates, got:\n%s", args)
+	}
+
+	// 캐시에서도 후보 목록을 그대로 가져온다
+	second := writeTestPNG(t, dir, "Screenshot 2025-01-16 at 09.00.00.png", true)
+	cached := ProcessScreenshot(cfg, second)
+	if !cached.NameCached || len(cached.Alternatives) != 1 {
+		t.Errorf("cached result = %+v, want cached with alternatives", cached)
+	}
+}
+
+func TestSwitchName(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "Screenshot 2025-01-15 at 12.30.45.png")
+	current := filepath.Join(dir, "2025-01-15_slack-chat.png")
+	if err := os.WriteFile(current, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{}
+	result := RenameResult{
+		OriginalPath: original, NewPath: current, Success: true,
+		SuggestedName: "slack-chat", Alternatives: []string{"slack-배포-논의", "slack-dm"},
+	}
+
+	switched := SwitchName(cfg, result, "slack-dm", nil)
+	if switched.Error != nil {
+		t.Fatal(switched.Error)
+	}
+	want := filepath.Join(dir, "2025-01-15_slack-dm.png")
+	if switched.NewPath != want || switched.SuggestedName != "slack-dm" {
+		t.Errorf("switched = %q (%q), want %q", switched.NewPath, switched.SuggestedName, want)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("switched file should exist: %v", err)
+	}
+	if _, err := os.Stat(current); !os.IsNotExist(err) {
+		t.Error("previous name should be gone")
+	}
+	if got := strings.Join(switched.Alternatives, ","); got != "slack-배포-논의,slack-chat" {
+		t.Errorf("Alternatives = %q, previous name should return to the list", got)
+	}
+	if result.Alternatives[1] != "slack-dm" {
+		t.Error("SwitchName should not modify the caller's alternatives")
+	}
+
+	if again := SwitchName(cfg, switched, "unknown", nil); again.Error == nil {
+		t.Error("unknown candidate should fail")
+	}
+}
+
+func TestRenameTo(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	original := filepath.Join(dir, "Screenshot 2025-01-15 at 12.30.45.png")
+	current := filepath.Join(dir, "2025-01-15_slack-chat.png")
+	if err := os.WriteFile(current, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result := RenameResult{OriginalPath: original, NewPath: current, Success: true, SuggestedName: "slack-chat"}
+
+	renamed := RenameTo(Config{MaxFileNameLen: 80, NameCase: NameCaseKebab}, result, "Slack 배포 논의", nil)
+	if renamed.Error != nil {
+		t.Fatal(renamed.Error)
+	}
+	want := filepath.Join(dir, "2025-01-15_slack-배포-논의.png")
+	if renamed.NewPath != want || renamed.SuggestedName != "slack-배포-논의" {
+		t.Errorf("renamed = %q (%q), want %q", renamed.NewPath, renamed.SuggestedName, want)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("renamed file should exist: %v", err)
+	}
+
+	if again := RenameTo(Config{}, RenameResult{OriginalPath: original}, "x", nil); again.Error == nil {
+		t.Error("renaming a failed result should fail")
+	}
+}
+
+func TestUndoRename(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "Screenshot 2025-01-15 at 12.30.45.png")
+	current := filepath.Join(dir, "2025-01-15_slack-chat.png")
+	if err := os.WriteFile(current, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result := RenameResult{OriginalPath: original, NewPath: current, Success: true}
+
+	undone := UndoRename(result)
+	if undone.Error != nil || !undone.Undone || undone.NewPath != original {
+		t.Fatalf("undone = %+v", undone)
+	}
+	if _, err := os.Stat(original); err != nil {
+		t.Errorf("original should be back: %v", err)
+	}
+	if again := UndoRename(undone); again.Error == nil {
+		t.Error("undoing twice should fail")
+	}
+
+	// 원래 이름으로 새 파일이 생겼으면 덮어쓰지 않는다
+	if err := os.WriteFile(current, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if blocked := UndoRename(result); blocked.Error == nil {
+		t.Error("undo over an existing file should fail")
+	}
+	if _, err := os.Stat(current); err != nil {
+		t.Errorf("renamed file should stay when undo fails: %v", err)
+	}
+}
+
+func TestMoveFileAcrossDevices(t *testing.T) {
+	other, err := os.MkdirTemp("/dev/shm", "move-test")
+	if err != nil {
+		t.Skip("no second filesystem to move across")
+	}
+	defer os.RemoveAll(other)
+	probe := filepath.Join(t.TempDir(), "probe")
+	os.WriteFile(probe, nil, 0644)
+	if err := os.Rename(probe, filepath.Join(other, "probe")); !errors.Is(err, syscall.EXDEV) {
+		t.Skip("temp dir and /dev/shm are on the same filesystem")
+	}
+
+	src := filepath.Join(t.TempDir(), "Screenshot 2025-01-15 at 12.30.45.png")
+	if err := os.WriteFile(src, []byte("png"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	taken := time.Date(2025, 1, 15, 12, 30, 45, 0, time.Local)
+	os.Chtimes(src, taken, taken)
+
+	dst := filepath.Join(other, "2025-01-15_slack-chat.png")
+	if err := moveFile(src, dst); err != nil {
+		t.Fatalf("moveFile across devices: %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("source should be removed after copying")
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 || !info.ModTime().Equal(taken) {
+		t.Errorf("moved file mode/mtime = %v %v, want 0640 %v", info.Mode().Perm(), info.ModTime(), taken)
+	}
+}
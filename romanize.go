@@ -0,0 +1,204 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalization은 파일명의 유니코드 정규화 형식이다.
+// macOS(APFS 이전)와 SMB는 한글을 NFD로, 대부분의 도구는 NFC로 다루므로 동기화 대상에 맞춰 고른다.
+type Normalization string
+
+const (
+	NormalizationNFC Normalization = "nfc"
+	NormalizationNFD Normalization = "nfd"
+)
+
+// 한글 음절/자모 범위 (유니코드 표준 3.12 Hangul Syllable Composition)
+const (
+	hangulBase  = 0xAC00
+	hangulLast  = 0xD7A3
+	jamoLBase   = 0x1100
+	jamoVBase   = 0x1161
+	jamoTBase   = 0x11A7
+	jamoLCount  = 19
+	jamoVCount  = 21
+	jamoTCount  = 28
+	jamoNCount  = jamoVCount * jamoTCount
+	jamoLSilent = 11 // ㅇ
+	jamoLRieul  = 5  // 초성 ㄹ
+	jamoTRieul  = 8  // 종성 ㄹ
+)
+
+// 국어의 로마자 표기법 (문화관광부 고시 제2000-8호) 초성, 중성, 종성
+var (
+	romanInitials = [jamoLCount]string{"g", "kk", "n", "d", "tt", "r", "m", "b", "pp", "s", "ss", "", "j", "jj", "ch", "k", "t", "p", "h"}
+	romanVowels   = [jamoVCount]string{"a", "ae", "ya", "yae", "eo", "e", "yeo", "ye", "o", "wa", "wae", "oe", "yo", "u", "wo", "we", "wi", "yu", "eu", "ui", "i"}
+	// 받침 (뒤에 자음이 오거나 단어 끝)
+	romanFinals = [jamoTCount]string{"", "k", "k", "k", "n", "n", "n", "t", "l", "k", "m", "l", "l", "l", "p", "l", "m", "p", "p", "t", "t", "ng", "t", "t", "k", "t", "p", "t"}
+	// 뒤 음절이 ㅇ으로 시작해 받침이 넘어가는 경우 (연음: 한국어 → hangugeo)
+	romanLinkedFinals = [jamoTCount]string{"", "g", "kk", "gs", "n", "nj", "n", "d", "r", "lg", "lm", "lb", "ls", "lt", "lp", "r", "m", "b", "bs", "s", "ss", "ng", "j", "ch", "k", "t", "p", ""}
+)
+
+// 히라가나 헵번식 표기. 가타카나는 히라가나로 바꿔서 찾는다.
+var kanaRomaji = map[string]string{
+	"あ": "a", "い": "i", "う": "u", "え": "e", "お": "o",
+	"か": "ka", "き": "ki", "く": "ku", "け": "ke", "こ": "ko",
+	"さ": "sa", "し": "shi", "す": "su", "せ": "se", "そ": "so",
+	"た": "ta", "ち": "chi", "つ": "tsu", "て": "te", "と": "to",
+	"な": "na", "に": "ni", "ぬ": "nu", "ね": "ne", "の": "no",
+	"は": "ha", "ひ": "hi", "ふ": "fu", "へ": "he", "ほ": "ho",
+	"ま": "ma", "み": "mi", "む": "mu", "め": "me", "も": "mo",
+	"や": "ya", "ゆ": "yu", "よ": "yo",
+	"ら": "ra", "り": "ri", "る": "ru", "れ": "re", "ろ": "ro",
+	"わ": "wa", "ゐ": "i", "ゑ": "e", "を": "o", "ん": "n",
+	"が": "ga", "ぎ": "gi", "ぐ": "gu", "げ": "ge", "ご": "go",
+	"ざ": "za", "じ": "ji", "ず": "zu", "ぜ": "ze", "ぞ": "zo",
+	"だ": "da", "ぢ": "ji", "づ": "zu", "で": "de", "ど": "do",
+	"ば": "ba", "び": "bi", "ぶ": "bu", "べ": "be", "ぼ": "bo",
+	"ぱ": "pa", "ぴ": "pi", "ぷ": "pu", "ぺ": "pe", "ぽ": "po",
+	"ゔ": "vu", "ぁ": "a", "ぃ": "i", "ぅ": "u", "ぇ": "e", "ぉ": "o",
+	"ゃ": "ya", "ゅ": "yu", "ょ": "yo", "ゎ": "wa",
+	"きゃ": "kya", "きゅ": "kyu", "きょ": "kyo", "しゃ": "sha", "しゅ": "shu", "しょ": "sho",
+	"ちゃ": "cha", "ちゅ": "chu", "ちょ": "cho", "にゃ": "nya", "にゅ": "nyu", "にょ": "nyo",
+	"ひゃ": "hya", "ひゅ": "hyu", "ひょ": "hyo", "みゃ": "mya", "みゅ": "myu", "みょ": "myo",
+	"りゃ": "rya", "りゅ": "ryu", "りょ": "ryo", "ぎゃ": "gya", "ぎゅ": "gyu", "ぎょ": "gyo",
+	"じゃ": "ja", "じゅ": "ju", "じょ": "jo", "びゃ": "bya", "びゅ": "byu", "びょ": "byo",
+	"ぴゃ": "pya", "ぴゅ": "pyu", "ぴょ": "pyo",
+	// 외래어 표기용 가타카나 조합
+	"ふぁ": "fa", "ふぃ": "fi", "ふぇ": "fe", "ふぉ": "fo", "てぃ": "ti", "でぃ": "di",
+	"うぃ": "wi", "うぇ": "we", "うぉ": "wo", "しぇ": "she", "じぇ": "je", "ちぇ": "che",
+}
+
+// 라틴 확장 문자와 키릴 문자 음역
+var letterTransliterations = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a", 'æ': "ae",
+	'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ı': "i",
+	'ł': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ß': "ss", 'ť': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// toASCII는 한글을 로마자 표기법으로, 가나를 헵번식으로, 라틴 확장/키릴 문자를 음역한다.
+// 음역할 수 없는 문자(한자 등)는 지운다.
+func toASCII(s string) string {
+	s = norm.NFC.String(s)
+	runes := []rune(s)
+	var sb strings.Builder
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r < 0x80:
+			sb.WriteRune(r)
+		case isHangulSyllable(r):
+			sb.WriteString(romanizeSyllable(runes, i))
+		case isKana(r):
+			n := writeRomaji(&sb, runes[i:])
+			i += n - 1
+		default:
+			t, ok := letterTransliterations[unicode.ToLower(r)]
+			if !ok {
+				continue
+			}
+			if unicode.IsUpper(r) && t != "" {
+				t = strings.ToUpper(t[:1]) + t[1:]
+			}
+			sb.WriteString(t)
+		}
+	}
+	return sb.String()
+}
+
+func isHangulSyllable(r rune) bool {
+	return r >= hangulBase && r <= hangulLast
+}
+
+// romanizeSyllable은 runes[i] 음절을 앞뒤 음절에 따른 연음과 ㄹㄹ 규칙을 적용해 표기한다.
+func romanizeSyllable(runes []rune, i int) string {
+	l, v, t := splitSyllable(runes[i])
+
+	initial := romanInitials[l]
+	if i > 0 && isHangulSyllable(runes[i-1]) {
+		_, _, prevT := splitSyllable(runes[i-1])
+		switch {
+		case l == jamoLSilent && prevT != 0:
+			// 앞 받침이 넘어와서 소리 나므로 초성은 비운다 (받침 쪽에서 표기)
+		case l == jamoLRieul && prevT == jamoTRieul:
+			initial = "l" // 울릉 → ulleung
+		}
+	}
+
+	final := romanFinals[t]
+	if t != 0 && i+1 < len(runes) && isHangulSyllable(runes[i+1]) {
+		if nextL, _, _ := splitSyllable(runes[i+1]); nextL == jamoLSilent {
+			final = romanLinkedFinals[t]
+		}
+	}
+	return initial + romanVowels[v] + final
+}
+
+func splitSyllable(r rune) (l, v, t int) {
+	idx := int(r - hangulBase)
+	return idx / jamoNCount, (idx % jamoNCount) / jamoTCount, idx % jamoTCount
+}
+
+func isKana(r rune) bool {
+	return (r >= 0x3041 && r <= 0x3096) || (r >= 0x30A1 && r <= 0x30FA) || r == 'ー'
+}
+
+// toHiragana는 가타카나를 같은 소리의 히라가나로 바꾼다.
+func toHiragana(r rune) rune {
+	if r >= 0x30A1 && r <= 0x30F6 {
+		return r - 0x60
+	}
+	return r
+}
+
+// writeRomaji는 runes 앞부분의 가나를 표기하고 사용한 rune 수를 반환한다.
+// 촉음(っ)은 다음 자음을 겹치고, 장음(ー)은 앞 모음을 반복하지 않고 생략한다.
+func writeRomaji(sb *strings.Builder, runes []rune) int {
+	r := toHiragana(runes[0])
+	switch r {
+	case 'ー':
+		return 1
+	case 'っ':
+		if len(runes) > 1 && isKana(runes[1]) {
+			var next strings.Builder
+			n := writeRomaji(&next, runes[1:])
+			if romaji := next.String(); romaji != "" {
+				sb.WriteString(romaji[:1] + romaji)
+			}
+			return n + 1
+		}
+		return 1
+	}
+	if len(runes) > 1 {
+		if romaji, ok := kanaRomaji[string([]rune{r, toHiragana(runes[1])})]; ok {
+			sb.WriteString(romaji)
+			return 2
+		}
+	}
+	sb.WriteString(kanaRomaji[string(r)])
+	return 1
+}
+
+// encodeFilename은 ascii_filenames와 unicode_normalization 설정을 최종 파일명에 적용한다.
+func encodeFilename(name string, cfg Config) string {
+	if cfg.ASCIIFilenames {
+		return toASCII(name)
+	}
+	if cfg.Normalization == NormalizationNFD {
+		return norm.NFD.String(name)
+	}
+	return norm.NFC.String(name)
+}
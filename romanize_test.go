@@ -0,0 +1,155 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestToASCIIKorean(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		// 기본 자모
+		{"가", "ga"},
+		{"한글", "hangeul"},
+		{"서울", "seoul"},
+		{"부산", "busan"},
+		{"제주", "jeju"},
+		{"대화", "daehwa"},
+		{"슬랙", "seullaek"},
+		{"설정", "seoljeong"},
+		{"스크린샷", "seukeurinsyat"},
+		{"카카오톡", "kakaotok"},
+		{"회의록", "hoeuirok"},
+		{"의사", "uisa"},
+		{"왜", "wae"},
+		{"뭐", "mwo"},
+		{"귀", "gwi"},
+		{"얘기", "yaegi"},
+		{"계획", "gyehoek"},
+		// 된소리
+		{"깍두기", "kkakdugi"},
+		{"떡볶이", "tteokbokki"},
+		{"빵", "ppang"},
+		{"쌀", "ssal"},
+		{"짜장", "jjajang"},
+		// 받침 대표음
+		{"부엌", "bueok"},
+		{"옷", "ot"},
+		{"밖", "bak"},
+		{"앞", "ap"},
+		{"닭", "dak"},
+		// 연음
+		{"한국어", "hangugeo"},
+		{"음악", "eumak"},
+		{"옷을", "oseul"},
+		{"밖에", "bakke"},
+		{"좋아", "joa"},
+		{"읽어", "ilgeo"},
+		// ㄹㄹ
+		{"울릉", "ulleung"},
+		{"빨래", "ppallae"},
+		// 문장 부호, 영문, 숫자는 그대로
+		{"깃허브-PR-리뷰", "githeobeu-PR-ribyu"},
+		{"2025년-회의", "2025nyeon-hoeui"},
+		{"슬랙 프로젝트_대화", "seullaek peurojekteu_daehwa"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := toASCII(tt.input); got != tt.want {
+				t.Errorf("toASCII(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToASCIIOtherScripts(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"スクリーンショット", "sukurinshotto"},
+		{"きょうの-しゃしん", "kyouno-shashin"},
+		{"ファイル", "fairu"},
+		{"設定-せってい", "-settei"},
+		{"Café-Résumé", "Cafe-Resume"},
+		{"Straße", "Strasse"},
+		{"Привет", "Privet"},
+		{"日本", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := toASCII(tt.input); got != tt.want {
+				t.Errorf("toASCII(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnicodeNormalization(t *testing.T) {
+	nfc := "슬랙-대화-2025"
+	nfd := "\u1109\u1173\u11af\u1105\u1162\u11a8-\u1103\u1162\u1112\u116a-2025" // 첫가끝 자모
+
+	if got := encodeFilename(nfc, Config{Normalization: NormalizationNFD}); got != nfd {
+		t.Errorf("NFD = %q, want %q", got, nfd)
+	}
+	if got := encodeFilename(nfd, Config{Normalization: NormalizationNFC}); got != nfc {
+		t.Errorf("NFC = %q, want %q", got, nfc)
+	}
+	if got := encodeFilename(nfc, Config{}); got != nfc {
+		t.Errorf("NFC of NFC = %q, want unchanged", got)
+	}
+	if got := toASCII(nfd); got != "seullaek-daehwa-2025" {
+		t.Errorf("toASCII(NFD) = %q", got)
+	}
+
+	// NFD로 들어온 출력도 정제 후에는 NFC (결합 문자가 지워지지 않고 합쳐진다)
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"hangul", nfd, nfc},
+		{"latin accent", "cafe\u0301-menu", "café-menu"},
+		{"kana voiced mark", "か\u3099いき\u3099", "がいぎ"},
+		{"lone jamo", "\u1100x", "\u1100x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeFilename(tt.input, 80); got != tt.want {
+				t.Errorf("SanitizeFilename(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessScreenshotFilenameEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		output   string
+		original string
+		want     string
+	}{
+		{"ascii kebab", Config{ASCIIFilenames: true, NameCase: NameCaseKebab}, "슬랙-프로젝트-대화", "Screenshot 2025-01-15 at 12.30.45.png", "2025-01-15_seullaek-peurojekteu-daehwa.png"},
+		{"ascii original", Config{ASCIIFilenames: true, NameTemplate: "{name}_{original}", Presets: []string{"macos-ko"}}, "대화", "스크린샷 2025-01-15 오후 12.30.45.png", "daehwa_seukeurinsyat 2025-01-15 ohu 12.30.45.png"},
+		{"ascii unromanizable", Config{ASCIIFilenames: true}, "日本", "Screenshot 2025-01-15 at 12.30.45.png", "2025-01-15_screenshot.png"},
+		{"nfd", Config{Normalization: NormalizationNFD}, "대화", "Screenshot 2025-01-15 at 12.30.45.png", "2025-01-15_대화.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			dir := t.TempDir()
+			shot := writeTestPNG(t, dir, tt.original, true)
+			claude, _ := fakeCLI(t, tt.output)
+			cfg := tt.cfg
+			cfg.Provider, cfg.ClaudePath, cfg.MaxFileNameLen, cfg.OCRPolicy = ProviderClaude, claude, 80, OCRPolicyNever
+
+			result := ProcessScreenshot(cfg, shot)
+			if want := filepath.Join(dir, tt.want); result.NewPath != want {
+				t.Errorf("NewPath = %q, want %q (err %v)", result.NewPath, want, result.Error)
+			}
+		})
+	}
+}
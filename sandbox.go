@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 샌드박스에 복사한 이미지 이름 (원본 파일명은 프로바이더에 넘기지 않는다)
+const sandboxImageName = "screenshot"
+
+// 샌드박스 안의 CLI에 넘겨줄 환경 변수. 나머지는 지운다.
+var sandboxEnvKeys = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "LANG", "TERM",
+	"XDG_CONFIG_HOME", "NODE_EXTRA_CA_CERTS",
+	"HTTPS_PROXY", "HTTP_PROXY", "NO_PROXY", "https_proxy", "http_proxy", "no_proxy",
+}
+
+// 이름이 이 접두사로 시작하는 환경 변수도 넘겨준다 (로케일, 인증 설정)
+var sandboxEnvPrefixes = []string{"LC_", "ANTHROPIC_", "CLAUDE_"}
+
+// Claude CLI에서 막는 도구
+var sandboxDeniedTools = []string{"Bash", "Edit", "MultiEdit", "Write", "NotebookEdit", "WebFetch", "WebSearch", "Task"}
+
+// Claude CLI가 사용자 설정 대신 읽는 설정 디렉토리에 복사하는 로그인 정보 파일
+const claudeCredentialsFile = ".credentials.json"
+
+// jobSandbox는 프로바이더 호출 한 번을 위한 임시 디렉토리다.
+// 이미지 사본 하나만 들어있고, CLI는 이 디렉토리를 작업 디렉토리로 실행된다.
+type jobSandbox struct {
+	dir   string
+	image string
+	// CLI에 CLAUDE_CONFIG_DIR로 넘길 격리된 설정 디렉토리 (비어 있으면 넘기지 않음)
+	claudeConfig string
+}
+
+// newSandbox는 임시 디렉토리를 만들고 이미지를 복사한다. 사용 후 Close로 지워야 한다.
+func newSandbox(imagePath string) (*jobSandbox, error) {
+	dir, err := os.MkdirTemp("", "auto-naming-capture-job-*")
+	if err != nil {
+		return nil, fmt.Errorf("create sandbox: %w", err)
+	}
+	sb := &jobSandbox{dir: dir, image: filepath.Join(dir, sandboxImageName+strings.ToLower(filepath.Ext(imagePath)))}
+	if err := copyFile(imagePath, sb.image); err != nil {
+		sb.Close()
+		return nil, fmt.Errorf("copy image to sandbox: %w", err)
+	}
+	return sb, nil
+}
+
+// Close는 샌드박스 디렉토리를 통째로 지운다.
+func (s *jobSandbox) Close() error {
+	return os.RemoveAll(s.dir)
+}
+
+// allowedTools는 샌드박스의 이미지 한 장만 읽을 수 있는 권한 규칙이다.
+func (s *jobSandbox) allowedTools() string {
+	return "Read(./" + filepath.Base(s.image) + ")"
+}
+
+// env는 허용한 환경 변수만 남기고 임시 디렉토리와 Claude 설정 디렉토리를 샌드박스 것으로 바꾼다.
+func (s *jobSandbox) env(environ []string) []string {
+	var env []string
+	for _, kv := range environ {
+		key, _, _ := strings.Cut(kv, "=")
+		if key == "TMPDIR" || key == "CLAUDE_CONFIG_DIR" || !sandboxEnvAllowed(key) {
+			continue
+		}
+		env = append(env, kv)
+	}
+	env = append(env, "TMPDIR="+s.dir)
+	if s.claudeConfig != "" {
+		env = append(env, "CLAUDE_CONFIG_DIR="+s.claudeConfig)
+	}
+	return env
+}
+
+// claudeConfigDir는 Claude CLI 전용 설정 디렉토리다. 사용자의 ~/.claude 설정,
+// 허용된 도구, MCP 서버, 훅을 물려받지 않도록 CLI를 이 디렉토리로 실행한다.
+func claudeConfigDir() string {
+	return filepath.Join(configDir(), "claude")
+}
+
+// userClaudeConfigDir는 사용자가 평소 쓰는 Claude CLI 설정 디렉토리다.
+func userClaudeConfigDir() string {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".claude")
+}
+
+// prepareClaudeConfig는 격리된 설정 디렉토리를 만들고, 사용자 설정에서 로그인 정보 파일만
+// 복사한다 (더 새로울 때만). 설정과 권한 규칙은 복사하지 않는다.
+// macOS 키체인에 저장된 로그인은 복사할 수 없으므로 ANTHROPIC_API_KEY를 쓰거나
+// 이 디렉토리로 한 번 로그인해야 한다.
+func prepareClaudeConfig(dir, userDir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	src := filepath.Join(userDir, claudeCredentialsFile)
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return nil
+	}
+	dst := filepath.Join(dir, claudeCredentialsFile)
+	if dstInfo, err := os.Stat(dst); err == nil && !srcInfo.ModTime().After(dstInfo.ModTime()) {
+		return nil
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0600)
+}
+
+func sandboxEnvAllowed(key string) bool {
+	for _, k := range sandboxEnvKeys {
+		if key == k {
+			return true
+		}
+	}
+	for _, p := range sandboxEnvPrefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSandboxEnv(t *testing.T) {
+	sb := &jobSandbox{dir: "/tmp/job", claudeConfig: "/Users/me/.config/auto-naming-capture/claude"}
+	environ := []string{
+		"PATH=/usr/bin",
+		"HOME=/Users/me",
+		"LANG=ko_KR.UTF-8",
+		"LC_ALL=ko_KR.UTF-8",
+		"ANTHROPIC_API_KEY=sk-ant-xxx",
+		"CLAUDE_CONFIG_DIR=/Users/me/.claude",
+		"HTTPS_PROXY=http://proxy:8080",
+		"TMPDIR=/var/folders/xx",
+		"AWS_SECRET_ACCESS_KEY=secret",
+		"GITHUB_TOKEN=ghp_xxx",
+		"SSH_AUTH_SOCK=/tmp/agent.sock",
+		"OPENAI_API_KEY=sk-xxx",
+	}
+	want := []string{
+		"PATH=/usr/bin",
+		"HOME=/Users/me",
+		"LANG=ko_KR.UTF-8",
+		"LC_ALL=ko_KR.UTF-8",
+		"ANTHROPIC_API_KEY=sk-ant-xxx",
+		"HTTPS_PROXY=http://proxy:8080",
+		"TMPDIR=/tmp/job",
+		"CLAUDE_CONFIG_DIR=/Users/me/.config/auto-naming-capture/claude",
+	}
+	if got := sb.env(environ); !slices.Equal(got, want) {
+		t.Errorf("env = %q, want %q", got, want)
+	}
+}
+
+func TestPrepareClaudeConfig(t *testing.T) {
+	userDir := t.TempDir()
+	os.WriteFile(filepath.Join(userDir, ".credentials.json"), []byte(`{"token":"a"}`), 0600)
+	os.WriteFile(filepath.Join(userDir, "settings.json"), []byte(`{"permissions":{"allow":["Bash"]}}`), 0644)
+	dir := filepath.Join(t.TempDir(), "claude")
+
+	if err := prepareClaudeConfig(dir, userDir); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0700 {
+		t.Fatalf("config dir = %v, %v, want 0700 directory", info, err)
+	}
+	info, err := os.Stat(filepath.Join(dir, ".credentials.json"))
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("credentials = %v, %v, want 0600 copy", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "settings.json")); !os.IsNotExist(err) {
+		t.Error("user settings must not be copied")
+	}
+
+	// 사용자 쪽 로그인 정보가 더 새로우면 다시 복사한다
+	later := time.Now().Add(time.Hour)
+	os.WriteFile(filepath.Join(userDir, ".credentials.json"), []byte(`{"token":"b"}`), 0600)
+	os.Chtimes(filepath.Join(userDir, ".credentials.json"), later, later)
+	prepareClaudeConfig(dir, userDir)
+	if got, _ := os.ReadFile(filepath.Join(dir, ".credentials.json")); string(got) != `{"token":"b"}` {
+		t.Errorf("credentials = %s, want refreshed copy", got)
+	}
+
+	if err := prepareClaudeConfig(filepath.Join(t.TempDir(), "empty"), filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Errorf("missing user config should not fail: %v", err)
+	}
+}
+
+func TestNewSandbox(t *testing.T) {
+	src := writeTestPNG(t, t.TempDir(), "Screenshot 2025-01-15 at 12.30.45.PNG", false)
+
+	sb, err := newSandbox(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(sb.image) != sb.dir || filepath.Base(sb.image) != "screenshot.png" {
+		t.Errorf("image = %q, want screenshot.png inside %q", sb.image, sb.dir)
+	}
+	want, _ := os.ReadFile(src)
+	got, err := os.ReadFile(sb.image)
+	if err != nil || string(got) != string(want) {
+		t.Errorf("sandbox copy differs from original: %v", err)
+	}
+	entries, _ := os.ReadDir(sb.dir)
+	if len(entries) != 1 {
+		t.Errorf("sandbox should only contain the image, got %d entries", len(entries))
+	}
+	if sb.allowedTools() != "Read(./screenshot.png)" {
+		t.Errorf("allowedTools = %q", sb.allowedTools())
+	}
+
+	if err := sb.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(sb.dir); !os.IsNotExist(err) {
+		t.Errorf("sandbox dir should be removed: %v", err)
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Errorf("original should remain: %v", err)
+	}
+
+	if _, err := newSandbox(filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("newSandbox should fail for a missing image")
+	}
+}
+
+func TestGenerateWithClaudeSandbox(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script fake requires a POSIX shell")
+	}
+	record := t.TempDir()
+	claude := filepath.Join(t.TempDir(), "claude")
+	script := "#!/bin/sh\n" +
+		"pwd -P > " + record + "/pwd\n" +
+		"ls -A > " + record + "/ls\n" +
+		"env > " + record + "/env\n" +
+		"printf '%s\\n' \"$@\" > " + record + "/args\n" +
+		"printf 'slack-chat'\n"
+	if err := os.WriteFile(claude, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_TOKEN", "ghp_should_not_leak")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CLAUDE_CONFIG_DIR", filepath.Join(t.TempDir(), "user-claude"))
+
+	shot := writeTestPNG(t, t.TempDir(), "Screenshot 2025-01-15 at 12.30.45.png", false)
+	cfg := Config{Provider: ProviderClaude, ClaudePath: claude, MaxFileNameLen: 80}
+	if _, err := generateWithClaude(cfg, shot, newPromptData(cfg, shot, OCRResult{})); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(record, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(data))
+	}
+	pwd := read("pwd")
+	if !strings.Contains(filepath.Base(pwd), "auto-naming-capture-job-") {
+		t.Errorf("working directory = %q, want job sandbox", pwd)
+	}
+	if ls := read("ls"); ls != "screenshot.png" {
+		t.Errorf("sandbox contents = %q, want only screenshot.png", ls)
+	}
+	env := read("env")
+	if strings.Contains(env, "ghp_should_not_leak") {
+		t.Error("environment should be scrubbed")
+	}
+	if !strings.Contains(env, "TMPDIR=") {
+		t.Error("TMPDIR should point to the sandbox")
+	}
+	if !slices.Contains(strings.Split(env, "\n"), "CLAUDE_CONFIG_DIR="+claudeConfigDir()) {
+		t.Errorf("CLAUDE_CONFIG_DIR should point to the isolated config dir: %q", env)
+	}
+	args := read("args")
+	for _, want := range []string{"Read(./screenshot.png)", "Bash,Edit", "screenshot.png"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("args should contain %q: %q", want, args)
+		}
+	}
+	if strings.Contains(args, shot) {
+		t.Error("original path should not be passed to the CLI")
+	}
+	if _, err := os.Stat(pwd); !os.IsNotExist(err) {
+		t.Errorf("sandbox should be cleaned up: %v", err)
+	}
+}
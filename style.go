@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NameLanguage는 파일명 언어다. 비어 있으면 프로바이더가 자연스러운 쪽을 고른다.
+type NameLanguage string
+
+const (
+	NameLanguageKorean   NameLanguage = "ko"
+	NameLanguageEnglish  NameLanguage = "en"
+	NameLanguageJapanese NameLanguage = "ja"
+	// OCR 텍스트의 주된 문자(없으면 로케일)로 언어를 고른다
+	NameLanguageAuto NameLanguage = "auto"
+)
+
+// NameCase는 파일명 표기 방식이다. 비어 있으면 프로바이더 출력을 그대로 쓴다.
+type NameCase string
+
+const (
+	NameCaseKebab NameCase = "kebab"
+	NameCaseSnake NameCase = "snake"
+	NameCaseTitle NameCase = "title"
+	NameCaseLower NameCase = "lower"
+)
+
+// Title 표기에서 이 길이 이하의 모두 대문자인 단어는 약어로 보고 그대로 둔다 (API, HTTPS)
+const maxAcronymLen = 5
+
+// 설정이 없을 때 프롬프트에 쓰는 단어 수 범위
+const (
+	defaultPromptMinWords = 2
+	defaultPromptMaxWords = 5
+)
+
+var nameLanguageNames = map[NameLanguage]string{
+	NameLanguageKorean:   "한국어",
+	NameLanguageEnglish:  "영어",
+	NameLanguageJapanese: "일본어",
+}
+
+var nameCaseRules = map[NameCase]string{
+	NameCaseKebab: "소문자 kebab-case (예: slack-project-chat)",
+	NameCaseSnake: "소문자 snake_case (예: slack_project_chat)",
+	NameCaseTitle: "단어 첫 글자만 대문자, 하이픈 연결 (예: Slack-Project-Chat)",
+	NameCaseLower: "영문은 모두 소문자",
+}
+
+// resolveNameLanguage는 설정과 OCR 텍스트로 강제할 언어를 정한다. 빈 값이면 강제하지 않는다.
+func resolveNameLanguage(cfg Config, ocrResult OCRResult) NameLanguage {
+	if cfg.NameLanguage != NameLanguageAuto {
+		return cfg.NameLanguage
+	}
+	if lang := dominantLanguage(ocrResult.Text); lang != "" {
+		return lang
+	}
+	switch locale := strings.ToLower(promptLocale(cfg)); {
+	case strings.HasPrefix(locale, "ko"):
+		return NameLanguageKorean
+	case strings.HasPrefix(locale, "ja"):
+		return NameLanguageJapanese
+	default:
+		return NameLanguageEnglish
+	}
+}
+
+// dominantLanguage는 한글, 가나/한자, 라틴 문자 중 가장 많은 쪽의 언어다.
+// 가나가 하나라도 있으면 한자는 일본어로 센다.
+func dominantLanguage(text string) NameLanguage {
+	var hangul, kana, han, latin int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	japanese := kana
+	if kana > 0 {
+		japanese += han
+	}
+	switch {
+	case hangul == 0 && japanese == 0 && latin == 0:
+		return ""
+	case hangul >= japanese && hangul >= latin:
+		return NameLanguageKorean
+	case japanese >= latin:
+		return NameLanguageJapanese
+	default:
+		return NameLanguageEnglish
+	}
+}
+
+// checkNameStyle은 프로바이더 출력이 언어와 최소 단어 수 설정을 지키는지 검사한다.
+// 지키지 않는 후보는 GenerateNames가 버린다.
+func checkNameStyle(name string, lang NameLanguage, minWords int) error {
+	var hangul, japanese bool
+	for _, r := range name {
+		hangul = hangul || unicode.Is(unicode.Hangul, r)
+		japanese = japanese || unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Han)
+	}
+	switch {
+	case lang == NameLanguageEnglish && (hangul || japanese):
+		return fmt.Errorf("not %s", nameLanguageNames[lang])
+	case lang == NameLanguageKorean && !hangul:
+		return fmt.Errorf("not %s", nameLanguageNames[lang])
+	case lang == NameLanguageJapanese && !japanese:
+		return fmt.Errorf("not %s", nameLanguageNames[lang])
+	}
+	if words := len(nameWords(name)); minWords > 0 && words < minWords {
+		return fmt.Errorf("too few words (%d < %d)", words, minWords)
+	}
+	return nil
+}
+
+// applyNameStyle은 정제된 파일명에 최대 단어 수와 표기 방식을 적용한다.
+// Title 표기는 용어집의 정식 표기(iPhone, GitHub)와 약어의 대소문자를 유지한다.
+func applyNameStyle(name string, style NameCase, maxWords int, glossary Glossary) string {
+	words := nameWords(name)
+	if len(words) == 0 {
+		return name
+	}
+	if maxWords > 0 && len(words) > maxWords {
+		words = words[:maxWords]
+		name = strings.Join(words, "-")
+	}
+
+	switch style {
+	case NameCaseKebab:
+		return strings.ToLower(strings.Join(words, "-"))
+	case NameCaseSnake:
+		return strings.ToLower(strings.Join(words, "_"))
+	case NameCaseTitle:
+		keep := make(map[string]bool)
+		for canonical := range glossary {
+			for _, w := range nameWords(canonical) {
+				keep[w] = true
+			}
+		}
+		for i, w := range words {
+			words[i] = titleWord(w, keep)
+		}
+		return strings.Join(words, "-")
+	case NameCaseLower:
+		return strings.ToLower(name)
+	default:
+		return name
+	}
+}
+
+// titleWord는 첫 글자만 대문자로 하고 나머지는 소문자로 바꾼다.
+// 용어집 정식 표기에 있는 단어와 짧은 약어는 그대로 둔다.
+func titleWord(w string, keep map[string]bool) string {
+	if keep[w] || isAcronym(w) {
+		return w
+	}
+	r, size := utf8.DecodeRuneInString(w)
+	return string(unicode.ToUpper(r)) + strings.ToLower(w[size:])
+}
+
+// isAcronym은 w가 maxAcronymLen 이하의 대문자로만 된 단어인지 (숫자 허용) 여부다.
+func isAcronym(w string) bool {
+	letters := 0
+	for _, r := range w {
+		switch {
+		case unicode.IsUpper(r):
+			letters++
+		case unicode.IsLetter(r):
+			return false
+		}
+	}
+	return letters >= 2 && letters <= maxAcronymLen
+}
+
+func nameWords(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || unicode.IsSpace(r)
+	})
+}
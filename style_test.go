@@ -0,0 +1,157 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDominantLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want NameLanguage
+	}{
+		{"슬랙 프로젝트 대화", NameLanguageKorean},
+		{"Slack 채널에서 배포 일정 논의", NameLanguageKorean},
+		{"Pull request review comments", NameLanguageEnglish},
+		{"設定画面のスクリーンショット", NameLanguageJapanese},
+		{"東京 支店", ""},
+		{"123 456", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := dominantLanguage(tt.text); got != tt.want {
+			t.Errorf("dominantLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestResolveNameLanguage(t *testing.T) {
+	ko := OCRResult{Text: "슬랙 대화", HasText: true}
+	tests := []struct {
+		name string
+		cfg  Config
+		ocr  OCRResult
+		want NameLanguage
+	}{
+		{"unset", Config{}, ko, ""},
+		{"forced", Config{NameLanguage: NameLanguageEnglish}, ko, NameLanguageEnglish},
+		{"auto from OCR", Config{NameLanguage: NameLanguageAuto, OCRLanguages: []string{"en-US"}}, ko, NameLanguageKorean},
+		{"auto from locale", Config{NameLanguage: NameLanguageAuto, OCRLanguages: []string{"ja-JP"}}, OCRResult{}, NameLanguageJapanese},
+		{"auto default english", Config{NameLanguage: NameLanguageAuto, OCRLanguages: []string{"de-DE"}}, OCRResult{}, NameLanguageEnglish},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveNameLanguage(tt.cfg, tt.ocr); got != tt.want {
+				t.Errorf("resolveNameLanguage = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckNameStyle(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		lang     NameLanguage
+		minWords int
+		wantErr  bool
+	}{
+		{"any language", "슬랙-대화", "", 0, false},
+		{"english ok", "slack-chat", NameLanguageEnglish, 0, false},
+		{"english with hangul", "slack-대화", NameLanguageEnglish, 0, true},
+		{"english with kanji", "設定-settings", NameLanguageEnglish, 0, true},
+		{"korean ok with product name", "GitHub-PR-리뷰", NameLanguageKorean, 0, false},
+		{"korean missing hangul", "github-pr-review", NameLanguageKorean, 0, true},
+		{"japanese ok", "設定画面-スクリーンショット", NameLanguageJapanese, 0, false},
+		{"japanese missing", "settings", NameLanguageJapanese, 0, true},
+		{"enough words", "slack-project-chat", "", 3, false},
+		{"too few words", "slack", "", 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkNameStyle(tt.input, tt.lang, tt.minWords)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkNameStyle(%q) = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyNameStyle(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		style    NameCase
+		maxWords int
+		want     string
+	}{
+		{"unchanged", "GitHub_PR-Review", "", 0, "GitHub_PR-Review"},
+		{"kebab", "GitHub_PR Review", NameCaseKebab, 0, "github-pr-review"},
+		{"snake", "GitHub-PR-Review", NameCaseSnake, 0, "github_pr_review"},
+		{"title", "github-pr_review", NameCaseTitle, 0, "Github-Pr-Review"},
+		{"title keeps acronyms", "slack-API-docs", NameCaseTitle, 0, "Slack-API-Docs"},
+		{"title lowercases rest", "iPHONE-SETTINGS-screen", NameCaseTitle, 0, "Iphone-Settings-Screen"},
+		{"title long caps not acronym", "GITHUBACTIONS-log", NameCaseTitle, 0, "Githubactions-Log"},
+		{"title acronym with digits", "S3-bucket-HTTPS", NameCaseTitle, 0, "S3-Bucket-HTTPS"},
+		{"lower keeps separators", "GitHub_PR-Review", NameCaseLower, 0, "github_pr-review"},
+		{"korean unaffected", "슬랙-프로젝트-대화", NameCaseKebab, 0, "슬랙-프로젝트-대화"},
+		{"max words", "slack-project-chat-window-today", "", 3, "slack-project-chat"},
+		{"max words with style", "Slack_Project_Chat_Window", NameCaseSnake, 2, "slack_project"},
+		{"under max", "slack-chat", NameCaseKebab, 5, "slack-chat"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyNameStyle(tt.input, tt.style, tt.maxWords, nil); got != tt.want {
+				t.Errorf("applyNameStyle(%q, %q, %d) = %q, want %q", tt.input, tt.style, tt.maxWords, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyNameStyleGlossary(t *testing.T) {
+	glossary := Glossary{"iPhone": {"아이폰"}, "GitHub Actions": nil}
+	got := applyNameStyle("iPhone-GitHub-Actions-LOGFILE", NameCaseTitle, 0, glossary)
+	if want := "iPhone-GitHub-Actions-Logfile"; got != want {
+		t.Errorf("applyNameStyle with glossary = %q, want %q", got, want)
+	}
+}
+
+func TestSystemPromptStyle(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := Config{NameLanguage: NameLanguageEnglish, NameCase: NameCaseKebab, NameMinWords: 2, NameMaxWords: 4, OCRLanguages: []string{"ko-KR"}}
+	system := buildSystemPrompt(newPromptData(cfg, "/img.png", OCRResult{}))
+	for _, want := range []string{"2-4단어", "영어로만 작성", "표기: 소문자 kebab-case"} {
+		if !strings.Contains(system, want) {
+			t.Errorf("system prompt should contain %q: %q", want, system)
+		}
+	}
+}
+
+func TestProcessScreenshotNameStyle(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		cfg      Config
+		wantName string
+	}{
+		{"lowercase english kebab", "Slack Project Chat Window Today", Config{NameLanguage: NameLanguageEnglish, NameCase: NameCaseKebab, NameMaxWords: 3}, "2025-01-15_slack-project-chat.png"},
+		{"wrong language falls back", "슬랙-대화", Config{NameLanguage: NameLanguageEnglish}, "2025-01-15_screenshot.png"},
+		{"too few words falls back", "slack", Config{NameMinWords: 2}, "2025-01-15_screenshot.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			dir := t.TempDir()
+			shot := writeTestPNG(t, dir, "Screenshot 2025-01-15 at 12.30.45.png", true)
+			claude, _ := fakeCLI(t, tt.output)
+			cfg := tt.cfg
+			cfg.Provider, cfg.ClaudePath, cfg.MaxFileNameLen, cfg.OCRPolicy = ProviderClaude, claude, 80, OCRPolicyNever
+
+			result := ProcessScreenshot(cfg, shot)
+			if want := filepath.Join(dir, tt.wantName); result.NewPath != want {
+				t.Errorf("NewPath = %q, want %q (err %v)", result.NewPath, want, result.Error)
+			}
+		})
+	}
+}
@@ -1,27 +1,88 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 )
 
-// 영어/한국어 macOS 스크린샷 파일명 패턴
-var screenshotPatterns = []*regexp.Regexp{
-	regexp.MustCompile(`^Screenshot \d{4}-\d{2}-\d{2}.*\.(png|jpg|jpeg)$`),
-	regexp.MustCompile(`^스크린샷 \d{4}-\d{2}-\d{2}.*\.(png|jpg|jpeg)$`),
-}
+// Rename 이벤트 직후의 Create 이벤트를 같은 이동으로 간주하는 시간 간격 (fsnotify).
+// 같은 파일인지는 파일 정보로 확인하므로 폴링 감시는 폴링 간격에 맞춰 늘린다.
+const renamePairWindow = time.Second
+
+// 직접 리네이밍한 파일의 이벤트를 무시하는 기간
+const ownRenameTTL = 10 * time.Second
+
+// 리네이밍한 결과 파일을 사용자가 고치는지 지켜보는 기간과 최대 개수
+const (
+	correctionWindow  = 24 * time.Hour
+	maxTrackedOutputs = 500
+)
 
 type Watcher struct {
-	cfg        *Config
-	cfgLock    *sync.Mutex
-	fsWatcher  *fsnotify.Watcher
-	onRenamed  func(RenameResult)
+	cfg       *Config
+	cfgLock   *sync.Mutex
+	fsWatcher *fsnotify.Watcher
+	onRenamed func(RenameResult)
+	// 결과 파일을 사용자가 다시 이름 바꾼 경우 (리네이밍 결과, 바뀐 경로)
+	onCorrected func(Config, RenameResult, string)
+
+	mu         sync.Mutex
 	processing map[string]bool
+	// 처리 중인 원본 경로 → 파일 정보 (이동 짝짓기에서 같은 파일인지 확인)
+	sources map[string]os.FileInfo
+	// 최근 Rename 이벤트가 발생한 경로 → 발생 시각 (이동 전 이름)
+	renamedFrom map[string]time.Time
+	pairWindow  time.Duration
+	// 직접 리네이밍한 원본/결과 경로 → 만료 시각
+	ownPaths map[string]time.Time
+	// 리네이밍한 결과 경로 → 결과 (사용자 수정 감지용, correctionWindow 동안 유지)
+	outputs map[string]trackedOutput
+	// 현재 감시 중인 디렉토리 (recursive 하위 디렉토리 포함)
+	watches    map[string]bool
+	maxWatches int
+}
+
+type trackedOutput struct {
+	result RenameResult
+	until  time.Time
+	// 등록할 때의 파일 정보 (이동 짝짓기에서 같은 파일인지 확인)
+	info os.FileInfo
+}
+
+// FileWatcher는 감시 디렉토리에 나타난 스크린샷을 감지해 리네이밍한다.
+// fsnotify 기반 Watcher와 폴링 기반 PollWatcher가 구현한다.
+type FileWatcher interface {
+	Start() error
+	Stop()
+	// Ignore는 앱이 직접 옮길 파일 경로를 잠시 처리 대상에서 뺀다 (알림의 되돌리기 등).
+	// 앱이 옮긴 파일은 사용자 수정으로 기록하지 않는다.
+	Ignore(path string)
+	// Track은 앱이 다른 이름으로 옮긴 결과 파일을 사용자 수정 감지 대상으로 다시 등록한다.
+	Track(result RenameResult)
+}
+
+// NewFileWatcher는 설정의 watch_mode에 맞는 감시자를 만든다.
+// auto 모드에서는 알림을 지원하지 않는 파일 시스템(SMB/NFS/FUSE 등)이 있으면 폴링을 사용한다.
+func NewFileWatcher(cfg *Config, cfgLock *sync.Mutex, onRenamed func(RenameResult)) (FileWatcher, error) {
+	cfgLock.Lock()
+	mode := cfg.WatchMode
+	dirs := cfg.Dirs()
+	cfgLock.Unlock()
+
+	if mode == WatchModePoll || (mode != WatchModeNotify && needsPolling(dirs)) {
+		return NewPollWatcher(cfg, cfgLock, onRenamed), nil
+	}
+	return NewWatcher(cfg, cfgLock, onRenamed)
 }
 
 func NewWatcher(cfg *Config, cfgLock *sync.Mutex, onRenamed func(RenameResult)) (*Watcher, error) {
@@ -29,24 +90,48 @@ func NewWatcher(cfg *Config, cfgLock *sync.Mutex, onRenamed func(RenameResult))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create watcher: %w", err)
 	}
+	return newWatcher(cfg, cfgLock, fsw, onRenamed), nil
+}
 
+func newWatcher(cfg *Config, cfgLock *sync.Mutex, fsw *fsnotify.Watcher, onRenamed func(RenameResult)) *Watcher {
 	return &Watcher{
-		cfg:        cfg,
-		cfgLock:    cfgLock,
-		fsWatcher:  fsw,
-		onRenamed:  onRenamed,
-		processing: make(map[string]bool),
-	}, nil
+		cfg:         cfg,
+		cfgLock:     cfgLock,
+		fsWatcher:   fsw,
+		onRenamed:   onRenamed,
+		processing:  make(map[string]bool),
+		sources:     make(map[string]os.FileInfo),
+		renamedFrom: make(map[string]time.Time),
+		pairWindow:  renamePairWindow,
+		ownPaths:    make(map[string]time.Time),
+		outputs:     make(map[string]trackedOutput),
+		watches:     make(map[string]bool),
+		onCorrected: recordCorrection,
+	}
 }
 
 func (w *Watcher) Start() error {
-	if err := w.fsWatcher.Add(w.cfg.ScreenshotDir); err != nil {
-		return fmt.Errorf("failed to watch %s: %w", w.cfg.ScreenshotDir, err)
+	w.cfgLock.Lock()
+	dirs := w.cfg.Dirs()
+	w.maxWatches = w.cfg.MaxWatches
+	w.cfgLock.Unlock()
+
+	// 일부 디렉토리를 감시하지 못해도 나머지는 계속 감시한다
+	var errs []error
+	for _, d := range dirs {
+		path := expandHome(d.Path)
+		if err := w.addTree(d, path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		watcherLog.Info("감시 시작", "dir", path)
+	}
+	if len(errs) == len(dirs) {
+		return errors.Join(errs...)
 	}
-	fmt.Printf("[Watcher] 감시 시작: %s\n", w.cfg.ScreenshotDir)
 
 	go w.loop()
-	return nil
+	return errors.Join(errs...)
 }
 
 func (w *Watcher) Stop() {
@@ -60,57 +145,369 @@ func (w *Watcher) loop() {
 			if !ok {
 				return
 			}
-			if event.Has(fsnotify.Create) {
-				w.handleCreate(event.Name)
+			// 이동해 들어온 파일은 새 이름으로 Create, 이전 이름으로 Rename 이벤트가 온다
+			switch {
+			case event.Has(fsnotify.Create):
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					w.handleCreateDir(event.Name)
+				} else {
+					w.handleCreate(event.Name)
+				}
+			case event.Has(fsnotify.Rename):
+				w.removeTree(event.Name)
+				w.handleRename(event.Name)
+			case event.Has(fsnotify.Remove):
+				w.removeTree(event.Name)
 			}
 		case err, ok := <-w.fsWatcher.Errors:
 			if !ok {
 				return
 			}
-			fmt.Printf("[Watcher] error: %v\n", err)
+			watcherLog.Error("fsnotify 오류", "err", err)
 		}
 	}
 }
 
-func (w *Watcher) handleCreate(path string) {
-	filename := filepath.Base(path)
+// addTree는 디렉토리를 감시 목록에 추가한다. recursive 설정이면 제외 패턴에
+// 해당하지 않는 모든 하위 디렉토리도 함께 추가한다.
+func (w *Watcher) addTree(d WatchDir, root string) error {
+	if !d.Recursive {
+		return w.addWatch(root)
+	}
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return fmt.Errorf("failed to watch %s: %w", root, err)
+			}
+			// 읽을 수 없는 하위 디렉토리는 건너뜀
+			watcherLog.Warn("하위 디렉토리 건너뜀", "dir", path, "err", err)
+			return nil
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path != root && !w.watchesDir(d, path) {
+			return filepath.SkipDir
+		}
+		return w.addWatch(path)
+	})
+}
+
+func (w *Watcher) addWatch(path string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.watches[path] {
+		return nil
+	}
+	if w.maxWatches > 0 && len(w.watches) >= w.maxWatches {
+		return fmt.Errorf("watch limit reached (max_watches=%d) at %s", w.maxWatches, path)
+	}
+	if err := w.fsWatcher.Add(path); err != nil {
+		if errors.Is(err, syscall.ENOSPC) {
+			return fmt.Errorf("OS watch limit reached at %s (raise fs.inotify.max_user_watches): %w", path, err)
+		}
+		return fmt.Errorf("failed to watch %s: %w", path, err)
+	}
+	w.watches[path] = true
+	return nil
+}
 
-	if !isScreenshot(filename) {
+// removeTree는 삭제되거나 이동된 디렉토리와 그 하위 디렉토리의 감시를 해제한다.
+func (w *Watcher) removeTree(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	prefix := path + string(filepath.Separator)
+	for p := range w.watches {
+		if p == path || strings.HasPrefix(p, prefix) {
+			// 커널이 이미 감시를 해제했을 수 있으므로 에러는 무시
+			w.fsWatcher.Remove(p)
+			delete(w.watches, p)
+		}
+	}
+}
+
+// handleCreateDir은 recursive 감시 디렉토리 아래에 새로 생긴 디렉토리를 감시에 추가한다.
+func (w *Watcher) handleCreateDir(path string) {
+	w.cfgLock.Lock()
+	d, ok := w.cfg.DirFor(path)
+	w.cfgLock.Unlock()
+	if !ok || !d.Recursive || !w.watchesDir(d, path) {
+		return
+	}
+	if err := w.addTree(d, path); err != nil {
+		watcherLog.Error("하위 디렉토리 감시 실패", "dir", path, "err", err)
 		return
 	}
+	watcherLog.Info("하위 디렉토리 감시 추가", "dir", path)
+}
 
-	// 이미 처리 중인 파일은 무시
-	if w.processing[path] {
+// watchesDir은 recursive 감시 디렉토리 d의 하위 디렉토리 path를 감시할지 정한다.
+// 제외 패턴에 해당하거나 중복 스크린샷을 옮기는 폴더면 감시하지 않는다.
+func (w *Watcher) watchesDir(d WatchDir, path string) bool {
+	if !d.Contains(path) {
+		return false
+	}
+	w.cfgLock.Lock()
+	defer w.cfgLock.Unlock()
+	return !isDuplicatesDir(*w.cfg, path)
+}
+
+func (w *Watcher) handleRename(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.renamedFrom[path] = time.Now()
+}
+
+func (w *Watcher) handleCreate(path string) {
+	if !w.claim(path) {
 		return
 	}
-	w.processing[path] = true
 
-	fmt.Printf("[Watcher] 스크린샷 감지: %s\n", filename)
+	watcherLog.Info("스크린샷 감지", "path", path)
 
 	// 500ms 대기 후 비동기 처리 (파일 쓰기 완료 대기)
 	go func() {
+		// 맨 앞 앱은 처리 시점이 아니라 감지 시점에 기록한다
+		app := w.captureApp(path)
 		time.Sleep(500 * time.Millisecond)
-		defer func() { delete(w.processing, path) }()
+		var result RenameResult
+		defer func() { w.release(path, result) }()
 
 		// 현재 설정의 스냅샷을 lock 하에 복사
 		w.cfgLock.Lock()
-		snapshot := *w.cfg
+		d, _ := w.cfg.DirFor(path)
+		snapshot := w.cfg.ForDir(d)
 		w.cfgLock.Unlock()
 
 		if !snapshot.Enabled {
-			fmt.Println("[Watcher] 비활성 상태 - 건너뜀")
+			watcherLog.Info("비활성 상태 - 건너뜀", "path", path)
 			return
 		}
 
-		result := ProcessScreenshot(snapshot, path)
+		result = processCapture(snapshot, path, app, w.Ignore)
 		if w.onRenamed != nil {
 			w.onRenamed(result)
 		}
 	}()
 }
 
+// captureApp은 path의 감시 디렉토리에 앱 조건 privacy 규칙이 있으면 맨 앞 앱을 반환한다.
+func (w *Watcher) captureApp(path string) string {
+	w.cfgLock.Lock()
+	d, _ := w.cfg.DirFor(path)
+	needed := privacyNeedsApp(w.cfg.ForDir(d))
+	w.cfgLock.Unlock()
+	if !needed {
+		return ""
+	}
+	return frontmostApp()
+}
+
+// claim은 새로 나타난 파일을 처리 대상으로 등록한다.
+// 스크린샷이 아니거나, 이미 처리 중이거나, 직접 리네이밍한 결과라면 false를 반환한다.
+func (w *Watcher) claim(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	w.expire(now)
+
+	// 직전 Rename 이벤트와 짝을 지어 이동(rename/move)으로 들어온 파일인지 확인
+	from, moved := w.pairRename(path, now)
+
+	// 앱이 직접 옮긴 파일 (리네이밍, 다른 후보, 알림의 이름 바꾸기/되돌리기)은 수정이 아니다.
+	// 결과 파일을 옮긴 경우라면 앱이 Track으로 새 경로를 다시 등록한다.
+	if _, own := w.ownPaths[path]; own {
+		if moved {
+			delete(w.outputs, from)
+		}
+		return false
+	}
+	if moved {
+		// 리네이밍한 결과 파일에서 이동 = 사용자가 이름을 고친 것
+		if out, ok := w.outputs[from]; ok {
+			// 원래 이름으로 돌아간 것은 수정이 아니라 되돌리기다
+			if path == out.result.OriginalPath {
+				delete(w.outputs, from)
+				w.ownPaths[path] = now.Add(ownRenameTTL)
+				return false
+			}
+			w.correct(from, path, out)
+			return false
+		}
+		// 처리 중이거나 처리한 원본에서 이동된 파일 = 직접 리네이밍한 결과
+		if _, own := w.ownPaths[from]; own || w.processing[from] {
+			w.ownPaths[path] = now.Add(ownRenameTTL)
+			return false
+		}
+	}
+
+	if !w.matches(path) {
+		return false
+	}
+	if moved {
+		watcherLog.Info("이동 감지", "from", from, "path", path)
+	}
+	// 이미 처리 중인 파일은 무시
+	if w.processing[path] {
+		return false
+	}
+	w.processing[path] = true
+	if info, err := os.Stat(path); err == nil {
+		w.sources[path] = info
+	}
+	return true
+}
+
+// release는 처리가 끝난 파일을 정리하고, 리네이밍 결과로 생길 이벤트를 무시하도록 기록한다.
+func (w *Watcher) release(path string, result RenameResult) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.processing, path)
+	delete(w.sources, path)
+	if result.Success {
+		until := time.Now().Add(ownRenameTTL)
+		w.ownPaths[result.OriginalPath] = until
+		w.ownPaths[result.NewPath] = until
+		if !result.Deleted && result.NewPath != "" {
+			w.track(result.NewPath, result)
+		}
+	}
+}
+
+func (w *Watcher) Ignore(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ownPaths[path] = time.Now().Add(ownRenameTTL)
+}
+
+func (w *Watcher) Track(result RenameResult) {
+	if !result.Success || result.Deleted || result.Undone || result.NewPath == "" {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.track(result.NewPath, result)
+}
+
+// track은 리네이밍한 결과 파일을 사용자 수정 감지 대상으로 등록한다.
+// 개수가 넘치면 가장 먼저 만료될 항목을 버린다.
+func (w *Watcher) track(path string, result RenameResult) {
+	if len(w.outputs) >= maxTrackedOutputs {
+		var oldest string
+		var oldestUntil time.Time
+		for p, out := range w.outputs {
+			if oldest == "" || out.until.Before(oldestUntil) {
+				oldest, oldestUntil = p, out.until
+			}
+		}
+		delete(w.outputs, oldest)
+	}
+	info, _ := os.Stat(path)
+	w.outputs[path] = trackedOutput{result: result, until: time.Now().Add(correctionWindow), info: info}
+}
+
+// correct는 결과 파일의 수동 리네이밍을 기록하고 onCorrected를 호출한다.
+// 같은 파일을 다시 고칠 수도 있으므로 새 경로로 계속 추적한다.
+func (w *Watcher) correct(from, path string, out trackedOutput) {
+	watcherLog.Info("수동 수정 감지", "from", from, "path", path)
+	delete(w.outputs, from)
+	w.outputs[path] = out
+
+	if w.onCorrected == nil {
+		return
+	}
+	w.cfgLock.Lock()
+	d, _ := w.cfg.DirFor(path)
+	snapshot := w.cfg.ForDir(d)
+	w.cfgLock.Unlock()
+	go w.onCorrected(snapshot, out.result, path)
+}
+
+// pairRename은 같은 디렉토리에서 최근에 발생한 Rename 이벤트 중 path와 같은 파일이었던 것을 찾아 소비한다.
+// fsnotify는 inotify cookie를 알려주지 않으므로 처리 중인 원본과 추적 중인 결과 파일의
+// 파일 정보(장치/inode)로 확인한다. 알 수 없는 파일의 Rename과는 짝짓지 않는다
+// (결과 파일을 폴더 밖으로 옮긴 직후 찍은 스크린샷을 수정으로 오인하지 않도록).
+func (w *Watcher) pairRename(path string, now time.Time) (string, bool) {
+	dir := filepath.Dir(path)
+	var info os.FileInfo
+	var from string
+	var latest time.Time
+	for p, at := range w.renamedFrom {
+		if filepath.Dir(p) != dir || now.Sub(at) > w.pairWindow || !at.After(latest) {
+			continue
+		}
+		src := w.sources[p]
+		if out, ok := w.outputs[p]; ok {
+			src = out.info
+		}
+		if src == nil {
+			continue
+		}
+		if info == nil {
+			var err error
+			if info, err = os.Stat(path); err != nil {
+				return "", false
+			}
+		}
+		if sameFile(src, info) {
+			from, latest = p, at
+		}
+	}
+	if from == "" {
+		return "", false
+	}
+	delete(w.renamedFrom, from)
+	return from, true
+}
+
+// sameFile은 이동 전후의 정보가 같은 파일인지 확인한다. 지운 파일의 inode는 새 파일에
+// 다시 쓰일 수 있으므로 이동으로 바뀌지 않는 크기와 수정 시각도 비교한다.
+func sameFile(a, b os.FileInfo) bool {
+	return os.SameFile(a, b) && a.Size() == b.Size() && a.ModTime().Equal(b.ModTime())
+}
+
+func (w *Watcher) expire(now time.Time) {
+	for p, at := range w.renamedFrom {
+		if now.Sub(at) > w.pairWindow {
+			delete(w.renamedFrom, p)
+		}
+	}
+	for p, until := range w.ownPaths {
+		if now.After(until) {
+			delete(w.ownPaths, p)
+		}
+	}
+	for p, out := range w.outputs {
+		if now.After(out.until) {
+			delete(w.outputs, p)
+		}
+	}
+}
+
+// matches는 파일이 속한 감시 디렉토리의 파일명 패턴과 일치하는지 확인한다.
+func (w *Watcher) matches(path string) bool {
+	w.cfgLock.Lock()
+	d, ok := w.cfg.DirFor(path)
+	patterns := w.cfg.ForDir(d).ScreenshotPatterns()
+	w.cfgLock.Unlock()
+	if !ok {
+		return false
+	}
+	return matchesPatterns(filepath.Base(path), patterns)
+}
+
+// isScreenshot은 기본 프리셋(영어/한국어 macOS) 기준으로 스크린샷인지 확인한다.
 func isScreenshot(filename string) bool {
-	for _, p := range screenshotPatterns {
+	return matchesPatterns(filename, defaultPatterns())
+}
+
+func matchesPatterns(filename string, patterns []*regexp.Regexp) bool {
+	for _, p := range patterns {
 		if p.MatchString(filename) {
 			return true
 		}
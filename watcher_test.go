@@ -1,6 +1,14 @@
 package main
 
-import "testing"
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
 
 func TestIsScreenshot(t *testing.T) {
 	tests := []struct {
@@ -55,3 +63,482 @@ func TestIsScreenshot(t *testing.T) {
 		})
 	}
 }
+
+func newTestWatcher(dir string) *Watcher {
+	return &Watcher{
+		cfg:         &Config{ScreenshotDir: dir},
+		cfgLock:     &sync.Mutex{},
+		processing:  make(map[string]bool),
+		renamedFrom: make(map[string]time.Time),
+		ownPaths:    make(map[string]time.Time),
+		outputs:     make(map[string]trackedOutput),
+		sources:     make(map[string]os.FileInfo),
+		pairWindow:  renamePairWindow,
+	}
+}
+
+// testRename은 실제 파일을 옮기고 감시자에 Rename 이벤트를 전달한다.
+func testRename(t *testing.T, w *Watcher, from, to string) {
+	t.Helper()
+	if err := os.Rename(from, to); err != nil {
+		t.Fatal(err)
+	}
+	w.handleRename(from)
+}
+
+func TestWatcherClaim(t *testing.T) {
+	dir := t.TempDir()
+	shot := filepath.Join(dir, "Screenshot 2025-01-15 at 12.30.45.png")
+	cleanup := func(t *testing.T, path string) {
+		t.Cleanup(func() { os.Remove(path) })
+	}
+	write := func(t *testing.T, path string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte("png"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cleanup(t, path)
+	}
+
+	t.Run("created screenshot", func(t *testing.T) {
+		w := newTestWatcher(dir)
+		write(t, shot)
+		if !w.claim(shot) {
+			t.Error("new screenshot should be claimed")
+		}
+	})
+
+	t.Run("already processing", func(t *testing.T) {
+		w := newTestWatcher(dir)
+		write(t, shot)
+		w.claim(shot)
+		if w.claim(shot) {
+			t.Error("screenshot in progress should not be claimed twice")
+		}
+	})
+
+	t.Run("non screenshot", func(t *testing.T) {
+		w := newTestWatcher(dir)
+		if w.claim(filepath.Join(dir, "photo.png")) {
+			t.Error("non screenshot should not be claimed")
+		}
+	})
+
+	t.Run("renamed from hidden temp file", func(t *testing.T) {
+		// macOS는 .Screenshot ... 임시 파일을 만든 뒤 이름을 바꾼다
+		w := newTestWatcher(dir)
+		temp := filepath.Join(dir, ".Screenshot 2025-01-15 at 12.30.45.png")
+		write(t, temp)
+		cleanup(t, shot)
+		testRename(t, w, temp, shot)
+		if !w.claim(shot) {
+			t.Error("screenshot renamed from temp file should be claimed")
+		}
+	})
+
+	t.Run("moved in from synced folder", func(t *testing.T) {
+		w := newTestWatcher(dir)
+		write(t, shot)
+		w.handleRename(filepath.Join("/other", "Screenshot 2025-01-15 at 12.30.45.png"))
+		if !w.claim(shot) {
+			t.Error("screenshot moved in should be claimed")
+		}
+		if len(w.renamedFrom) != 1 {
+			t.Error("rename in other directory should not be paired")
+		}
+	})
+
+	t.Run("own rename while processing", func(t *testing.T) {
+		w := newTestWatcher(dir)
+		write(t, shot)
+		w.claim(shot)
+		// 결과 이름이 스크린샷 패턴과 겹치더라도 다시 처리하면 안 됨
+		renamed := filepath.Join(dir, "Screenshot 2025-01-15 renamed.png")
+		cleanup(t, renamed)
+		testRename(t, w, shot, renamed)
+		if w.claim(renamed) {
+			t.Error("file produced by our own rename should be ignored")
+		}
+	})
+
+	t.Run("own rename after release", func(t *testing.T) {
+		w := newTestWatcher(dir)
+		write(t, shot)
+		w.claim(shot)
+		newPath := filepath.Join(dir, "Screenshot 2025-01-15 renamed.png")
+		w.release(shot, RenameResult{OriginalPath: shot, NewPath: newPath, Success: true})
+		if w.processing[shot] {
+			t.Error("release should clear processing state")
+		}
+		if w.claim(newPath) {
+			t.Error("renamed output should be ignored")
+		}
+	})
+
+	t.Run("unrelated rename not paired", func(t *testing.T) {
+		// 처리 중인 파일이 다른 곳으로 옮겨진 직후 새 스크린샷이 생겨도 같은 파일이 아니다
+		w := newTestWatcher(dir)
+		write(t, shot)
+		os.Chtimes(shot, time.Time{}, time.Now().Add(-time.Minute))
+		w.claim(shot)
+		os.Remove(shot)
+		w.handleRename(shot)
+		other := filepath.Join(dir, "Screenshot 2025-01-16 at 09.00.00.png")
+		write(t, other)
+		if !w.claim(other) {
+			t.Error("new screenshot should not be paired with an unrelated rename")
+		}
+	})
+
+	t.Run("stale rename not paired", func(t *testing.T) {
+		w := newTestWatcher(dir)
+		write(t, shot)
+		w.claim(shot)
+		other := filepath.Join(dir, "Screenshot 2025-01-16 at 09.00.00.png")
+		cleanup(t, other)
+		testRename(t, w, shot, other)
+		w.renamedFrom[shot] = time.Now().Add(-2 * renamePairWindow)
+		if !w.claim(other) {
+			t.Error("rename outside pair window should not be matched")
+		}
+		if len(w.renamedFrom) != 0 {
+			t.Error("stale rename should expire")
+		}
+	})
+}
+
+func TestWatcherMatchesPerDirectory(t *testing.T) {
+	w := newTestWatcher("")
+	w.cfg = &Config{WatchDirs: []WatchDir{
+		{Path: "/desktop"},
+		{Path: "/captures", Patterns: []string{`^capture-\d+\.png$`}},
+		{Path: "/broken", Patterns: []string{`(`}},
+	}}
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"default patterns", "/desktop/Screenshot 2025-01-15 at 12.30.45.png", true},
+		{"custom pattern", "/captures/capture-1.png", true},
+		{"custom pattern replaces default", "/captures/Screenshot 2025-01-15 at 12.30.45.png", false},
+		{"invalid pattern falls back", "/broken/Screenshot 2025-01-15 at 12.30.45.png", true},
+		{"unwatched directory", "/other/Screenshot 2025-01-15 at 12.30.45.png", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := w.matches(tt.path); got != tt.want {
+				t.Errorf("matches(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatcherRecursive(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"project/shots", "node_modules/pkg", ".git/objects"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &Config{
+		WatchDirs: []WatchDir{{
+			Path:      root,
+			Recursive: true,
+			Exclude:   []string{"node_modules", ".git"},
+		}},
+	}
+	w, err := NewWatcher(cfg, &sync.Mutex{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+
+	watching := func(path string) bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return w.watches[path]
+	}
+	waitFor := func(path string, want bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if watching(path) == want {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Errorf("watching(%q) = %v, want %v", path, !want, want)
+	}
+
+	t.Run("existing subdirectories", func(t *testing.T) {
+		for _, dir := range []string{root, filepath.Join(root, "project"), filepath.Join(root, "project/shots")} {
+			if !watching(dir) {
+				t.Errorf("%s should be watched", dir)
+			}
+		}
+	})
+
+	t.Run("excluded subdirectories", func(t *testing.T) {
+		for _, dir := range []string{"node_modules", "node_modules/pkg", ".git", ".git/objects"} {
+			if watching(filepath.Join(root, dir)) {
+				t.Errorf("%s should be excluded", dir)
+			}
+		}
+	})
+
+	t.Run("new subdirectory added and removed", func(t *testing.T) {
+		dir := filepath.Join(root, "project", "new")
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		waitFor(dir, true)
+
+		if err := os.Remove(dir); err != nil {
+			t.Fatal(err)
+		}
+		waitFor(dir, false)
+	})
+
+	t.Run("new excluded subdirectory ignored", func(t *testing.T) {
+		dir := filepath.Join(root, "project", "node_modules")
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(200 * time.Millisecond)
+		if watching(dir) {
+			t.Errorf("%s should be excluded", dir)
+		}
+	})
+}
+
+func TestWatcherRecursiveSkipsDuplicates(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "project", "duplicates"), 0755)
+
+	cfg := &Config{
+		WatchDirs:       []WatchDir{{Path: root, Recursive: true}},
+		DuplicateAction: DuplicateMove,
+	}
+	w, err := NewWatcher(cfg, &sync.Mutex{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	watching := func(path string) bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return w.watches[path]
+	}
+
+	if !watching(filepath.Join(root, "project")) {
+		t.Error("project should be watched")
+	}
+	if watching(filepath.Join(root, "project", "duplicates")) {
+		t.Error("existing duplicates folder should not be watched")
+	}
+
+	// 중복 스크린샷을 처음 옮길 때 만들어지는 폴더도 감시하지 않는다
+	dups := filepath.Join(root, "duplicates")
+	os.Mkdir(dups, 0755)
+	time.Sleep(200 * time.Millisecond)
+	if watching(dups) {
+		t.Error("new duplicates folder should not be watched")
+	}
+
+	// 옮긴 중복 스크린샷은 다시 처리하지 않는다
+	moved := filepath.Join(dups, "Screenshot 2025-01-15 at 12.30.45.png")
+	w.Ignore(moved)
+	os.WriteFile(moved, []byte("png"), 0644)
+	if w.claim(moved) {
+		t.Error("moved duplicate should not be claimed")
+	}
+}
+
+func TestWatcherMaxWatches(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a", "b", "c"} {
+		os.Mkdir(filepath.Join(root, dir), 0755)
+	}
+
+	cfg := &Config{
+		WatchDirs:  []WatchDir{{Path: root, Recursive: true}},
+		MaxWatches: 2,
+	}
+	w, err := NewWatcher(cfg, &sync.Mutex{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	err = w.Start()
+	if err == nil || !strings.Contains(err.Error(), "watch limit reached") {
+		t.Errorf("Start() error = %v, want watch limit error", err)
+	}
+}
+
+func TestWatcherCorrection(t *testing.T) {
+	dir := t.TempDir()
+	shot := filepath.Join(dir, "Screenshot 2025-01-15 at 12.30.45.png")
+	output := filepath.Join(dir, "2025-01-15_slack-chat.png")
+	corrected := filepath.Join(dir, "2025-01-15_Slack-chat.png")
+
+	renamed := func(t *testing.T, w *Watcher) chan string {
+		t.Helper()
+		for _, path := range []string{shot, output, corrected} {
+			t.Cleanup(func() { os.Remove(path) })
+		}
+		if err := os.WriteFile(shot, []byte("png"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got := make(chan string, 1)
+		w.onCorrected = func(_ Config, result RenameResult, path string) {
+			if result.NewPath != output {
+				t.Errorf("result.NewPath = %q, want %q", result.NewPath, output)
+			}
+			got <- path
+		}
+		w.claim(shot)
+		testRename(t, w, shot, output)
+		w.release(shot, RenameResult{OriginalPath: shot, NewPath: output, Success: true})
+		return got
+	}
+
+	t.Run("user renames output", func(t *testing.T) {
+		w := newTestWatcher(dir)
+		got := renamed(t, w)
+		testRename(t, w, output, corrected)
+		if w.claim(corrected) {
+			t.Error("corrected output should not be processed")
+		}
+		select {
+		case path := <-got:
+			if path != corrected {
+				t.Errorf("corrected path = %q, want %q", path, corrected)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("onCorrected not called")
+		}
+		if _, ok := w.outputs[corrected]; !ok {
+			t.Error("corrected file should stay tracked")
+		}
+	})
+
+	t.Run("own rename is not a correction", func(t *testing.T) {
+		w := newTestWatcher(dir)
+		got := renamed(t, w)
+		w.claim(output)
+		select {
+		case <-got:
+			t.Error("our own rename should not count as a correction")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("app rename is not a correction", func(t *testing.T) {
+		// 메뉴의 다른 후보, 알림의 이름 바꾸기는 옮기기 전에 Ignore를 호출한다
+		w := newTestWatcher(dir)
+		got := renamed(t, w)
+		w.Ignore(corrected)
+		testRename(t, w, output, corrected)
+		if w.claim(corrected) {
+			t.Error("app renamed output should not be processed")
+		}
+		select {
+		case <-got:
+			t.Error("app initiated rename should not count as a correction")
+		case <-time.After(50 * time.Millisecond):
+		}
+		if len(w.outputs) != 0 {
+			t.Errorf("moved output should stop being tracked: %v", w.outputs)
+		}
+
+		w.Track(RenameResult{OriginalPath: shot, NewPath: corrected, Success: true})
+		if _, ok := w.outputs[corrected]; !ok {
+			t.Error("Track should register the new path")
+		}
+	})
+
+	t.Run("output moved out then new screenshot", func(t *testing.T) {
+		// 결과 파일을 다른 폴더로 옮긴 직후 찍은 스크린샷은 수정이 아니다
+		w := newTestWatcher(dir)
+		got := renamed(t, w)
+		if err := os.Rename(output, filepath.Join(t.TempDir(), filepath.Base(output))); err != nil {
+			t.Fatal(err)
+		}
+		w.handleRename(output)
+		next := filepath.Join(dir, "Screenshot 2025-01-15 at 12.31.00.png")
+		if err := os.WriteFile(next, []byte("png"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.Remove(next) })
+		if !w.claim(next) {
+			t.Error("new screenshot should be processed")
+		}
+		select {
+		case <-got:
+			t.Error("new screenshot should not count as a correction")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		w := newTestWatcher(dir)
+		got := renamed(t, w)
+		out := w.outputs[output]
+		out.until = time.Now().Add(-time.Second)
+		w.outputs[output] = out
+		testRename(t, w, output, corrected)
+		w.claim(corrected)
+		select {
+		case <-got:
+			t.Error("rename after correction window should be ignored")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("undo is not a correction", func(t *testing.T) {
+		w := newTestWatcher(dir)
+		got := renamed(t, w)
+		clear(w.ownPaths)
+		testRename(t, w, output, shot)
+		if w.claim(shot) {
+			t.Error("undone screenshot should not be processed again")
+		}
+		select {
+		case <-got:
+			t.Error("moving back to the original name should not count as a correction")
+		case <-time.After(50 * time.Millisecond):
+		}
+		if len(w.outputs) != 0 {
+			t.Errorf("undone output should stop being tracked: %v", w.outputs)
+		}
+	})
+
+	t.Run("ignored path", func(t *testing.T) {
+		w := newTestWatcher(dir)
+		w.Ignore(shot)
+		if w.claim(shot) {
+			t.Error("ignored path should not be claimed")
+		}
+	})
+
+	t.Run("tracking is bounded", func(t *testing.T) {
+		w := newTestWatcher(dir)
+		for i := range maxTrackedOutputs + 10 {
+			w.track(filepath.Join(dir, fmt.Sprintf("out-%d.png", i)), RenameResult{})
+		}
+		if len(w.outputs) != maxTrackedOutputs {
+			t.Errorf("tracked %d outputs, want %d", len(w.outputs), maxTrackedOutputs)
+		}
+	})
+}